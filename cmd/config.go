@@ -2,6 +2,8 @@ package main
 
 import (
 	"time"
+
+	"github.com/scratchpay_ademola/internal/httputil"
 )
 
 // Config representation of the service configuration
@@ -9,12 +11,41 @@ type Config struct {
 	*GlobalConfig
 	Env  string `envconfig:"ENVIRONMENT" default:"development"`
 	Port int    `envconfig:"PORT" default:"8000"`
+
+	// SnapshotTTL is how long the clinic data is served before being fetched again from the providers
+	SnapshotTTL time.Duration `envconfig:"SNAPSHOT_TTL" default:"5m"`
+	// SnapshotRetain is the number of snapshot versions kept for cursors issued against older versions
+	SnapshotRetain int `envconfig:"SNAPSHOT_RETAIN" default:"5"`
+	// CursorSecret signs the pagination cursors, a random one is generated on startup when empty
+	CursorSecret string `envconfig:"CURSOR_SECRET"`
+	// SearchCacheEntries is the number of search results kept in the result cache, 0 disables the cache
+	SearchCacheEntries int `envconfig:"SEARCH_CACHE_ENTRIES" default:"1000"`
+	// SearchCacheMaxResults is the number of clinics above which a search result is not cached
+	SearchCacheMaxResults int `envconfig:"SEARCH_CACHE_MAX_RESULTS" default:"5000"`
+	// SynonymsFile is the synonym dictionary the searched names are expanded with, an empty path disables synonyms
+	SynonymsFile string `envconfig:"SYNONYMS_FILE" default:"config/synonyms.txt"`
+	// SynonymsReloadInterval is how often the synonym dictionary is checked for changes, 0 disables the reload
+	SynonymsReloadInterval time.Duration `envconfig:"SYNONYMS_RELOAD_INTERVAL" default:"30s"`
+	// AccessLogExclude holds the path prefixes of the requests left out of the access log
+	AccessLogExclude []string `envconfig:"ACCESS_LOG_EXCLUDE" default:"/alive,/health,/metrics,/debug/"`
+	// AccessLogSampleRates holds the share of the requests of a route logged, e.g. `/v1/clinics/search:0.1`
+	AccessLogSampleRates map[string]float64 `envconfig:"ACCESS_LOG_SAMPLE_RATES"`
+	// TraceSampleRate is the share of the traces sampled, unless the `traceparent` header of the request decides
+	TraceSampleRate float64 `envconfig:"TRACE_SAMPLE_RATE" default:"0.01"`
+	// RateLimits holds the requests a client is allowed per period on a route, by route pattern, e.g. `/v1/clinics/search:60/1m`
+	RateLimits map[string]httputil.RateLimit `envconfig:"RATE_LIMITS" default:"/v1/clinics/search:120/1m,/v1/clinics/search/batch:20/1m,/v1/clinics/facets:120/1m"`
+	// APIKeysFile holds the API keys accepted, one `<id> <sha256 hash> <scopes>` per line
+	APIKeysFile string `envconfig:"API_KEYS_FILE"`
+	// APIKeys holds API keys accepted along with the ones of APIKeysFile, in the same format separated by `;`
+	APIKeys string `envconfig:"API_KEYS"`
+	// AuthDisabled serves the clinic routes without an API key, the admin and debug routes still requiring one
+	AuthDisabled bool `envconfig:"AUTH_DISABLED" default:"false"`
 }
 
 // GlobalConfig represents common application parameters
 type GlobalConfig struct {
 	Port              int           `envconfig:"PORT"`
-	ClientTimeout     int           `envconfig:"CLIENT_TIMEOUT_SEC"`
+	ClientTimeout     int           `envconfig:"CLIENT_TIMEOUT_SEC" default:"10"`
 	ClientIdleTimeout time.Duration `envconfig:"CLIENT_IDLE_TIMEOUT"`
 	LogLevel          string        `envconfig:"LOG_LEVEL"`
 	AppEnv            string        `envconfig:"APP_ENV"`
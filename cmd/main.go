@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/scratchpay_ademola/internal/apikey"
 	"github.com/scratchpay_ademola/internal/httputil"
 	"github.com/scratchpay_ademola/internal/logger"
 	"github.com/scratchpay_ademola/internal/os/process"
@@ -13,6 +15,7 @@ import (
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/scratchpay_ademola/pkg/clinic"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 )
 
@@ -49,20 +52,62 @@ func main() {
 		httputil.TextHandler(http.StatusServiceUnavailable, "application/json", `"NOT READY"`),
 	)
 
+	apiKeys, err := apikey.Load(cfg.APIKeysFile, cfg.APIKeys)
+	if err != nil {
+		panic(fmt.Errorf("error loading API keys: %s", err))
+	}
+
+	auth := httputil.NewAuthenticator(apiKeys, cfg.AuthDisabled)
+	if !auth.Enabled() {
+		log.Warn("authentication is disabled, the clinic routes are served without an API key")
+	}
+
+	if apiKeys.Len() == 0 {
+		log.Warn("no API keys configured, the routes requiring an API key are denied")
+	}
+
 	mux := httputil.NewBaseMux(
 		ready.Handler(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`)),
+		auth,
 	)
 
-	clinicDataDownloader := clinic.NewDataDownloader(log)
+	clinicDataDownloader := clinic.NewDataDownloader(log, time.Duration(cfg.ClientTimeout)*time.Second)
+	clinicStore := clinic.NewSnapshotStore(clinicDataDownloader, cfg.SnapshotTTL, cfg.SnapshotRetain)
+
+	cursorSecret := []byte(cfg.CursorSecret)
+	if len(cursorSecret) == 0 {
+		log.Warn("no cursor secret configured, pagination cursors will not survive a restart")
+
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			panic(fmt.Errorf("error generating cursor secret: %s", err))
+		}
+	}
+
+	resultCache := clinic.NewResultCache(cfg.SearchCacheEntries, cfg.SearchCacheMaxResults)
+
+	synonyms, err := clinic.NewSynonymStore(cfg.SynonymsFile)
+	if err != nil {
+		panic(fmt.Errorf("error loading synonyms: %s", err))
+	}
+
+	go synonyms.Watch(ctx, cfg.SynonymsReloadInterval, log)
 
 	// init routes
-	routes := initRoutes(clinicDataDownloader)
+	routes := initRoutes(clinicStore, resultCache, synonyms, httputil.NewCursorCodec(cursorSecret), auth, httputil.NewRateLimiter(cfg.RateLimits))
 
 	mux.Handle("/", routes)
 
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(cfg.TraceSampleRate)})
+
+	accessLog := httputil.AccessLog(log, httputil.AccessLogConfig{
+		Exclude:     cfg.AccessLogExclude,
+		SampleRates: cfg.AccessLogSampleRates,
+	})
+
 	// init HTTP Server for API
 	httpServer := &http.Server{
-		Handler: mux,
+		Handler: httputil.RequestID(auth.Authenticate(httputil.Tracing(accessLog(httputil.Metrics(mux))))),
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
 	}
 
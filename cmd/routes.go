@@ -1,19 +1,32 @@
 package main
 
 import (
+	"github.com/scratchpay_ademola/internal/apikey"
+	"github.com/scratchpay_ademola/internal/httputil"
 	"github.com/scratchpay_ademola/pkg/clinic"
 
 	"github.com/go-chi/chi"
 )
 
 // initRoutes initialize the routing configuration and return a prepared http.Handler
-func initRoutes(fetcher clinic.DataFetcher) *chi.Mux {
+func initRoutes(store *clinic.SnapshotStore, cache *clinic.ResultCache, synonyms *clinic.SynonymStore, cursors *httputil.CursorCodec, auth *httputil.Authenticator, limiter *httputil.RateLimiter) *chi.Mux {
 	mux := chi.NewMux()
 
 	mux.Route("/v1/clinics", func(r chi.Router) {
-		r.Post("/search", clinic.Search(fetcher))
-		r.Get("/", clinic.GetAllClinics(fetcher))
+		// the rate limiter runs once the route is matched, it limits the requests by route pattern,
+		// unauthenticated requests included
+		r = r.With(limiter.Handler, auth.Require(apikey.ScopeClinicsRead))
+
+		r.Post("/search", clinic.Search(store, cache, synonyms, cursors))
+		r.Get("/search", clinic.Search(store, cache, synonyms, cursors))
+		r.Post("/search/batch", clinic.SearchBatch(store, cache, synonyms, cursors))
+		r.Get("/", clinic.GetAllClinics(store, cursors))
+		r.Get("/facets", clinic.GetFacets(store, cache, synonyms))
+
+		r.With(auth.Require(apikey.ScopeClinicsAdmin)).Post("/synonyms/reload", clinic.ReloadSynonyms(synonyms))
 	})
 
+	mux.Get(httputil.ErrorCatalogPath, httputil.ErrorCatalogHandler())
+
 	return mux
 }
@@ -8,9 +8,7 @@ require (
 	github.com/go-playground/universal-translator v0.17.0
 	github.com/go-playground/validator/v10 v10.6.1
 	github.com/kelseyhightower/envconfig v1.4.0
-	github.com/mitchellh/mapstructure v1.4.1
 	github.com/stretchr/testify v1.7.0
-	github.com/thedevsaddam/gojsonq/v2 v2.5.2
 	go.opencensus.io v0.23.0
 	go.uber.org/zap v1.17.0
 )
@@ -0,0 +1,172 @@
+package apikey
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Header is the HTTP header clients send their API key in, an `Authorization: Bearer` header is accepted as well
+const Header = "X-API-Key"
+
+// Scopes granted to the API keys
+const (
+	// ScopeClinicsRead allows listing and searching the clinics
+	ScopeClinicsRead = "clinics:read"
+	// ScopeClinicsAdmin allows administering the clinic search, e.g. reloading the synonyms, it implies ScopeClinicsRead
+	ScopeClinicsAdmin = "clinics:admin"
+	// ScopeDebug allows reading the metrics, traces and profiles of the service
+	ScopeDebug = "debug"
+)
+
+// implied holds the scopes granted along with a scope
+var implied = map[string][]string{
+	ScopeClinicsAdmin: {ScopeClinicsRead},
+}
+
+var knownScopes = map[string]bool{
+	ScopeClinicsRead:  true,
+	ScopeClinicsAdmin: true,
+	ScopeDebug:        true,
+}
+
+// Key is the identity of an API key, its ID is safe to log unlike the key itself
+type Key struct {
+	ID     string
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted the scope, directly or through a scope implying it
+func (k Key) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+
+		for _, i := range implied[s] {
+			if i == scope {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type contextKey struct{}
+
+// With returns a copy of the context holding the key
+func With(ctx context.Context, key Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// From returns the key held by the context, false when the request was not authenticated
+func From(ctx context.Context) (Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(Key)
+	return key, ok
+}
+
+// Hash returns the hex encoded SHA-256 hash of an API key, the form keys are configured and stored in
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Keys holds the API keys accepted, by hash
+type Keys struct {
+	keys map[string]Key
+}
+
+// Parse parses the API keys, one per line or separated by `;`, each given as `<id> <sha256 hash> <scope>[,<scope>]`.
+// Blank lines and lines starting with `#` are ignored.
+func Parse(r io.Reader) (*Keys, error) {
+	keys := &Keys{keys: make(map[string]Key)}
+	ids := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		for _, entry := range strings.Split(scanner.Text(), ";") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" || strings.HasPrefix(entry, "#") {
+				continue
+			}
+
+			fields := strings.Fields(entry)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("line %d: expected <id> <sha256 hash> <scopes>, got %d fields", line, len(fields))
+			}
+
+			id, hash, scopes := fields[0], strings.ToLower(fields[1]), strings.Split(fields[2], ",")
+
+			if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("line %d: the hash of key %s is not a hex encoded SHA-256 hash", line, id)
+			}
+
+			for _, scope := range scopes {
+				if !knownScopes[scope] {
+					return nil, fmt.Errorf("line %d: unknown scope %q of key %s", line, scope, id)
+				}
+			}
+
+			if ids[id] {
+				return nil, fmt.Errorf("line %d: key %s is declared twice", line, id)
+			}
+
+			if _, ok := keys.keys[hash]; ok {
+				return nil, fmt.Errorf("line %d: key %s has the hash of another key", line, id)
+			}
+
+			ids[id] = true
+			keys.keys[hash] = Key{ID: id, Scopes: scopes}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Load loads the API keys of the file at path, when not empty, along with the keys of env, see Parse
+func Load(path, env string) (*Keys, error) {
+	var sources []io.Reader
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		sources = append(sources, f, strings.NewReader("\n"))
+	}
+
+	sources = append(sources, strings.NewReader(env))
+
+	return Parse(io.MultiReader(sources...))
+}
+
+// Lookup returns the identity of the API key, false when the key is unknown
+func (k *Keys) Lookup(key string) (Key, bool) {
+	if k == nil {
+		return Key{}, false
+	}
+
+	identity, ok := k.keys[Hash(key)]
+	return identity, ok
+}
+
+// Len returns the number of API keys
+func (k *Keys) Len() int {
+	if k == nil {
+		return 0
+	}
+
+	return len(k.keys)
+}
@@ -0,0 +1,87 @@
+package apikey
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	hash := Hash("secret")
+
+	tests := []struct {
+		name  string
+		value string
+		want  int
+		err   bool
+	}{
+		{name: "parses a key per line", value: "# partners\nacme " + hash + " clinics:read\n\nops " + Hash("other") + " debug,clinics:admin\n", want: 2},
+		{name: "parses keys separated by semicolons", value: "acme " + hash + " clinics:read;ops " + Hash("other") + " debug", want: 2},
+		{name: "parses no keys", value: "", want: 0},
+		{name: "rejects missing scopes", value: "acme " + hash, err: true},
+		{name: "rejects plain keys", value: "acme secret clinics:read", err: true},
+		{name: "rejects unknown scopes", value: "acme " + hash + " clinics:write", err: true},
+		{name: "rejects duplicate IDs", value: "acme " + hash + " clinics:read;acme " + Hash("other") + " debug", err: true},
+		{name: "rejects duplicate keys", value: "acme " + hash + " clinics:read;ops " + hash + " debug", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := Parse(strings.NewReader(tt.value))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, keys.Len())
+		})
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	f, err := ioutil.TempFile("", "api_keys")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString("acme " + Hash("acme-secret") + " clinics:read")
+	f.Close()
+
+	keys, err := Load(f.Name(), "ops "+Hash("ops-secret")+" debug,clinics:admin")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+
+	key, ok := keys.Lookup("ops-secret")
+	assert.True(t, ok)
+	assert.Equal(t, "ops", key.ID)
+	assert.True(t, key.HasScope(ScopeDebug))
+	assert.True(t, key.HasScope(ScopeClinicsRead), "clinics:admin implies clinics:read")
+
+	_, ok = keys.Lookup(Hash("ops-secret"))
+	assert.False(t, ok, "the hash is not a key")
+
+	_, err = Load("/does/not/exist", "")
+	assert.Error(t, err)
+}
+
+func TestHasScope(t *testing.T) {
+	admin := Key{ID: "ops", Scopes: []string{ScopeDebug, ScopeClinicsAdmin}}
+	assert.True(t, admin.HasScope(ScopeDebug))
+	assert.True(t, admin.HasScope(ScopeClinicsAdmin))
+	assert.True(t, admin.HasScope(ScopeClinicsRead), "clinics:admin implies clinics:read")
+
+	reader := Key{ID: "acme", Scopes: []string{ScopeClinicsRead}}
+	assert.False(t, reader.HasScope(ScopeClinicsAdmin), "clinics:read doesn't imply clinics:admin")
+	assert.False(t, reader.HasScope(ScopeDebug))
+}
+
+func TestLookupWithoutKeys(t *testing.T) {
+	var keys *Keys
+
+	_, ok := keys.Lookup("secret")
+	assert.False(t, ok)
+	assert.Equal(t, 0, keys.Len())
+}
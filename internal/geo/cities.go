@@ -0,0 +1,85 @@
+package geo
+
+import "strings"
+
+// City is a city of a state, identified by its name and state code
+type City struct {
+	Name     string
+	State    string
+	Location Point
+}
+
+// Cities lists the larger cities of the states, clinics in other cities are located at the centroid of their state
+var Cities = []City{
+	{"New York", "NY", Point{40.71, -74.01}},
+	{"Los Angeles", "CA", Point{34.05, -118.24}},
+	{"Chicago", "IL", Point{41.88, -87.63}},
+	{"Houston", "TX", Point{29.76, -95.37}},
+	{"Phoenix", "AZ", Point{33.45, -112.07}},
+	{"Philadelphia", "PA", Point{39.95, -75.17}},
+	{"San Antonio", "TX", Point{29.42, -98.49}},
+	{"San Diego", "CA", Point{32.72, -117.16}},
+	{"Dallas", "TX", Point{32.78, -96.80}},
+	{"San Jose", "CA", Point{37.34, -121.89}},
+	{"Austin", "TX", Point{30.27, -97.74}},
+	{"Jacksonville", "FL", Point{30.33, -81.66}},
+	{"San Francisco", "CA", Point{37.77, -122.42}},
+	{"Columbus", "OH", Point{39.96, -83.00}},
+	{"Indianapolis", "IN", Point{39.77, -86.16}},
+	{"Seattle", "WA", Point{47.61, -122.33}},
+	{"Denver", "CO", Point{39.74, -104.99}},
+	{"Washington", "DC", Point{38.91, -77.04}},
+	{"Boston", "MA", Point{42.36, -71.06}},
+	{"Nashville", "TN", Point{36.16, -86.78}},
+	{"Las Vegas", "NV", Point{36.17, -115.14}},
+	{"Reno", "NV", Point{39.53, -119.81}},
+	{"Portland", "OR", Point{45.52, -122.68}},
+	{"Miami", "FL", Point{25.76, -80.19}},
+	{"Orlando", "FL", Point{28.54, -81.38}},
+	{"Tampa", "FL", Point{27.95, -82.46}},
+	{"Atlanta", "GA", Point{33.75, -84.39}},
+	{"Kansas City", "MO", Point{39.10, -94.58}},
+	{"St. Louis", "MO", Point{38.63, -90.20}},
+	{"Wichita", "KS", Point{37.69, -97.34}},
+	{"Sacramento", "CA", Point{38.58, -121.49}},
+	{"Salt Lake City", "UT", Point{40.76, -111.89}},
+	{"Minneapolis", "MN", Point{44.98, -93.27}},
+	{"Detroit", "MI", Point{42.33, -83.05}},
+	{"New Orleans", "LA", Point{29.95, -90.07}},
+	{"Albuquerque", "NM", Point{35.08, -106.65}},
+	{"Charlotte", "NC", Point{35.23, -80.84}},
+	{"Baltimore", "MD", Point{39.29, -76.61}},
+	{"Pittsburgh", "PA", Point{40.44, -80.00}},
+	{"Honolulu", "HI", Point{21.31, -157.86}},
+	{"Anchorage", "AK", Point{61.22, -149.90}},
+}
+
+// LookupCity returns the city with the given name in the state given by its code or name, ignoring case
+func LookupCity(name, state string) (City, bool) {
+	s, ok := LookupState(state)
+	if !ok {
+		return City{}, false
+	}
+
+	for _, city := range Cities {
+		if city.State == s.Code && strings.EqualFold(city.Name, strings.TrimSpace(name)) {
+			return city, true
+		}
+	}
+
+	return City{}, false
+}
+
+// Locate returns the location of a city of a state, falling back to the centroid of the state when the city is unknown
+func Locate(city, state string) (Point, bool) {
+	if c, ok := LookupCity(city, state); ok {
+		return c.Location, true
+	}
+
+	s, ok := LookupState(state)
+	if !ok {
+		return Point{}, false
+	}
+
+	return s.Centroid, true
+}
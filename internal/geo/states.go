@@ -0,0 +1,77 @@
+package geo
+
+import "strings"
+
+// State is a US state, district or territory clinics can be located in
+type State struct {
+	Code     string
+	Name     string
+	Centroid Point
+}
+
+// States lists the known states ordered by code, the centroids are approximate geographic centers
+var States = []State{
+	{"AK", "Alaska", Point{63.59, -154.49}},
+	{"AL", "Alabama", Point{32.32, -86.90}},
+	{"AR", "Arkansas", Point{35.20, -91.83}},
+	{"AZ", "Arizona", Point{34.05, -111.09}},
+	{"CA", "California", Point{36.78, -119.42}},
+	{"CO", "Colorado", Point{39.55, -105.78}},
+	{"CT", "Connecticut", Point{41.60, -73.09}},
+	{"DC", "District of Columbia", Point{38.91, -77.03}},
+	{"DE", "Delaware", Point{38.91, -75.53}},
+	{"FL", "Florida", Point{27.66, -81.52}},
+	{"GA", "Georgia", Point{32.17, -82.90}},
+	{"HI", "Hawaii", Point{19.90, -155.58}},
+	{"IA", "Iowa", Point{41.88, -93.10}},
+	{"ID", "Idaho", Point{44.07, -114.74}},
+	{"IL", "Illinois", Point{40.63, -89.40}},
+	{"IN", "Indiana", Point{40.27, -86.13}},
+	{"KS", "Kansas", Point{39.01, -98.48}},
+	{"KY", "Kentucky", Point{37.84, -84.27}},
+	{"LA", "Louisiana", Point{30.98, -91.96}},
+	{"MA", "Massachusetts", Point{42.41, -71.38}},
+	{"MD", "Maryland", Point{39.05, -76.64}},
+	{"ME", "Maine", Point{45.25, -69.45}},
+	{"MI", "Michigan", Point{44.31, -85.60}},
+	{"MN", "Minnesota", Point{46.73, -94.69}},
+	{"MO", "Missouri", Point{37.96, -91.83}},
+	{"MS", "Mississippi", Point{32.35, -89.40}},
+	{"MT", "Montana", Point{46.88, -110.36}},
+	{"NC", "North Carolina", Point{35.76, -79.02}},
+	{"ND", "North Dakota", Point{47.55, -101.00}},
+	{"NE", "Nebraska", Point{41.49, -99.90}},
+	{"NH", "New Hampshire", Point{43.19, -71.57}},
+	{"NJ", "New Jersey", Point{40.06, -74.41}},
+	{"NM", "New Mexico", Point{34.52, -105.87}},
+	{"NV", "Nevada", Point{38.80, -116.42}},
+	{"NY", "New York", Point{43.30, -74.22}},
+	{"OH", "Ohio", Point{40.42, -82.91}},
+	{"OK", "Oklahoma", Point{35.01, -97.09}},
+	{"OR", "Oregon", Point{43.80, -120.55}},
+	{"PA", "Pennsylvania", Point{41.20, -77.19}},
+	{"PR", "Puerto Rico", Point{18.22, -66.59}},
+	{"RI", "Rhode Island", Point{41.58, -71.48}},
+	{"SC", "South Carolina", Point{33.84, -81.16}},
+	{"SD", "South Dakota", Point{43.97, -99.90}},
+	{"TN", "Tennessee", Point{35.52, -86.58}},
+	{"TX", "Texas", Point{31.97, -99.90}},
+	{"UT", "Utah", Point{39.32, -111.09}},
+	{"VA", "Virginia", Point{37.43, -78.66}},
+	{"VT", "Vermont", Point{44.56, -72.58}},
+	{"WA", "Washington", Point{47.75, -120.74}},
+	{"WI", "Wisconsin", Point{43.78, -88.79}},
+	{"WV", "West Virginia", Point{38.60, -80.45}},
+	{"WY", "Wyoming", Point{43.08, -107.29}},
+}
+
+// LookupState returns the state with the given code or name, ignoring case
+func LookupState(s string) (State, bool) {
+	for _, state := range States {
+		if strings.EqualFold(state.Code, s) || strings.EqualFold(state.Name, s) {
+			return state, true
+		}
+	}
+
+	return State{}, false
+}
@@ -0,0 +1,54 @@
+package httputil
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/logger"
+	"go.uber.org/zap"
+)
+
+// AccessLogConfig configures the access log
+type AccessLogConfig struct {
+	// Exclude holds the path prefixes of the requests left out of the access log, e.g. `/health` or `/debug/`
+	Exclude []string
+	// SampleRates holds the share of the requests logged, between 0 and 1, keyed by route pattern,
+	// e.g. `/v1/clinics/search`. Routes without a rate are all logged, and so are failed requests.
+	SampleRates map[string]float64
+}
+
+// AccessLog is a middleware logging every request once it is served, with its method, path, route pattern, status,
+// response size, latency, remote address, user agent and request ID, see RequestID.
+//
+// The route pattern is the one matched by the chi router the request is served by, e.g. `/v1/clinics/{id}`,
+// or the pattern of the NewBaseMux route, `unmatched` when no route matched the request.
+func AccessLog(l *zap.Logger, cfg AccessLogConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range cfg.Exclude {
+				if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			req := serveObserved(next, w, r)
+
+			if rate, ok := cfg.SampleRates[req.route]; ok && req.status < http.StatusBadRequest && rand.Float64() >= rate {
+				return
+			}
+
+			logger.From(r.Context(), logger.WithBase(l)).Info("request served",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("route", req.route),
+				zap.Int("status", req.status),
+				zap.Int("bytes", req.bytes),
+				zap.Duration("latency", req.latency),
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package httputil
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/apikey"
+	"github.com/scratchpay_ademola/internal/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	codeUnauthenticated = NewErrorCode("AUTH_UNAUTHENTICATED", http.StatusUnauthorized, false, "missing or invalid API key")
+	codeForbidden       = NewErrorCode("AUTH_FORBIDDEN", http.StatusForbidden, false, "API key lacks the scope of the route")
+)
+
+// Authenticator authenticates the requests by API key and checks the scope each route requires.
+// It fails closed: without keys, every route requiring a scope is denied.
+type Authenticator struct {
+	keys     *apikey.Keys
+	disabled bool
+}
+
+// NewAuthenticator creates an Authenticator accepting the keys, a nil set of keys accepting none.
+// Disabling authentication serves the routes requiring the `clinics:read` scope without a key,
+// the routes requiring any other scope, e.g. `debug`, still require a key granted the scope.
+func NewAuthenticator(keys *apikey.Keys, disabled bool) *Authenticator {
+	return &Authenticator{keys: keys, disabled: disabled}
+}
+
+// Enabled reports whether the routes requiring the `clinics:read` scope are authenticated
+func (a *Authenticator) Enabled() bool {
+	return !a.disabled
+}
+
+// Authenticate is a middleware identifying the API key of the request, sent in the `X-API-Key` header
+// or as an `Authorization: Bearer` token. The identity of a known key is stored in the context of the request,
+// see apikey.From, and its ID is logged as `api_key` by the loggers of the request, see logger.From.
+// Requests without a known key are served anonymously and rejected by the routes requiring a scope.
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, ok := a.keys.Lookup(requestKey(r)); ok {
+			ctx := apikey.With(r.Context(), key)
+			r = r.WithContext(logger.WithFields(ctx, zap.String("api_key", key.ID)))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestKey returns the API key sent with the request, empty when there is none
+func requestKey(r *http.Request) string {
+	if key := r.Header.Get(apikey.Header); key != "" {
+		return key
+	}
+
+	const bearer = "Bearer "
+	if auth := r.Header.Get("Authorization"); len(auth) > len(bearer) && strings.EqualFold(auth[:len(bearer)], bearer) {
+		return strings.TrimSpace(auth[len(bearer):])
+	}
+
+	return ""
+}
+
+// Require returns a middleware serving the requests authenticated with a key granted the scope,
+// see Authenticate. Other requests are rejected with a 401 error, or a 403 error when the key lacks the scope.
+func (a *Authenticator) Require(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a.disabled && scope == apikey.ScopeClinicsRead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, ok := apikey.From(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				JSONError(w, r, codeUnauthenticated, nil)
+				return
+			}
+
+			if !key.HasScope(scope) {
+				JSONError(w, r, codeForbidden.WithMessage("API key lacks the "+scope+" scope"), nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
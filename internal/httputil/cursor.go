@@ -0,0 +1,71 @@
+package httputil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed or was not signed by the CursorCodec decoding it
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorCodec turns pagination state into opaque cursors and back.
+//
+// A cursor is the base64 encoded JSON of the state followed by its HMAC-SHA256 signature,
+// so clients can hand it back but cannot forge or alter it.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec returns a CursorCodec signing cursors with the given secret
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{
+		secret: secret,
+	}
+}
+
+// Encode returns the signed cursor of v
+func (c *CursorCodec) Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode verifies the signature of the cursor and unmarshals its state into v
+func (c *CursorCodec) Decode(cursor string, v interface{}) error {
+	parts := strings.SplitN(cursor, ".", 2)
+	if len(parts) != 2 {
+		return ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, c.sign(parts[0])) {
+		return ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrorCatalogPath is the path of the endpoint listing the error codes, problem types point to it
+const ErrorCatalogPath = "/v1/errors"
+
+// ErrorCode is a stable machine readable error clients can branch on, along with
+// the HTTP status it is returned with, whether retrying the same request may succeed, and its default message
+type ErrorCode struct {
+	Code      string `json:"code"`
+	Status    int    `json:"status"`
+	Retryable bool   `json:"retryable"`
+	Message   string `json:"message"`
+
+	// detail replaces the default message in a single response, see WithMessage
+	detail string
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = make(map[string]ErrorCode)
+)
+
+// NewErrorCode creates an error code and adds it to the catalog, it panics when the code is already taken
+func NewErrorCode(code string, status int, retryable bool, message string) ErrorCode {
+	c := ErrorCode{Code: code, Status: status, Retryable: retryable, Message: message}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if _, ok := catalog[code]; ok {
+		panic(fmt.Sprintf("error code %s is already registered", code))
+	}
+
+	catalog[code] = c
+
+	return c
+}
+
+// WithMessage returns the error code with the message of the response replaced, e.g. to name the invalid param
+func (c ErrorCode) WithMessage(message string) ErrorCode {
+	c.detail = message
+	return c
+}
+
+// message returns the message of the response, the default one unless replaced
+func (c ErrorCode) message() string {
+	if c.detail != "" {
+		return c.detail
+	}
+
+	return c.Message
+}
+
+// ErrorCatalog returns the error codes of the catalog ordered by code
+func ErrorCatalog() []ErrorCode {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	codes := make([]ErrorCode, 0, len(catalog))
+	for _, c := range catalog {
+		codes = append(codes, c)
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+
+	return codes
+}
+
+// ErrorCatalogHandler returns a HandlerFunc listing the error codes of the catalog
+func ErrorCatalogHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		JSONSuccess(w, http.StatusOK, map[string]interface{}{"data": ErrorCatalog()})
+	}
+}
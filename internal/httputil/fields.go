@@ -0,0 +1,233 @@
+package httputil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetFields returns the field paths of the `fields` query parameter, e.g. `fields=name,state,availability.from`
+func GetFields(r *http.Request) []string {
+	var paths []string
+
+	for _, path := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// ValidateFields checks that every dot separated path names a JSON field of the struct type t
+func ValidateFields(t reflect.Type, paths []string) error {
+	var unknown []string
+
+	for _, path := range paths {
+		if !hasField(t, strings.Split(path, ".")) {
+			unknown = append(unknown, fmt.Sprintf("%q", path))
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown fields %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+func hasField(t reflect.Type, path []string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if len(path) == 0 {
+		return true
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if name == path[0] {
+			return hasField(field.Type, path[1:])
+		}
+	}
+
+	return false
+}
+
+// Project returns the JSON representation of v reduced to the given dot separated field paths,
+// paths that are not present in v are left out
+func Project(v interface{}, paths []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var source map[string]interface{}
+	if err := json.Unmarshal(data, &source); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]interface{})
+	for _, path := range paths {
+		copyPath(projected, source, strings.Split(path, "."))
+	}
+
+	return projected, nil
+}
+
+func copyPath(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	child, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		dst[path[0]] = child
+	}
+
+	copyPath(child, nested, path[1:])
+}
+
+// LeafFields returns the dot separated paths of the fields of the struct type t holding values, the ones of the
+// given paths when there are any and else all of them, e.g. `availability` expands to `availability.from` and
+// `availability.to`. Paths are expected to be valid, see ValidateFields.
+func LeafFields(t reflect.Type, paths []string) []string {
+	if len(paths) == 0 {
+		return leafFields(t, "")
+	}
+
+	var leaves []string
+	for _, path := range paths {
+		leaves = append(leaves, leafFields(fieldType(t, strings.Split(path, ".")), path)...)
+	}
+
+	return leaves
+}
+
+func leafFields(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return []string{prefix}
+	}
+
+	var leaves []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		leaves = append(leaves, leafFields(field.Type, name)...)
+	}
+
+	return leaves
+}
+
+func fieldType(t reflect.Type, path []string) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if len(path) == 0 || t.Kind() != reflect.Struct {
+		return t
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]; name == path[0] || name == "" && field.Name == path[0] {
+			return fieldType(field.Type, path[1:])
+		}
+	}
+
+	return t
+}
+
+// Record returns the values of v at the given leaf paths as strings, in the order of the paths,
+// a value missing from the JSON representation of v is empty
+func Record(v interface{}, paths []string) ([]string, error) {
+	projected, err := Project(v, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(paths))
+	for i, path := range paths {
+		record[i] = formatValue(lookupPath(projected, strings.Split(path, ".")))
+	}
+
+	return record, nil
+}
+
+func lookupPath(m map[string]interface{}, path []string) interface{} {
+	value := m[path[0]]
+	if len(path) == 1 {
+		return value
+	}
+
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return lookupPath(nested, path[1:])
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,189 @@
+package httputil
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a representation a response can be rendered in
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// formatMediaTypes maps each format to its media type
+var formatMediaTypes = map[Format]string{
+	FormatJSON:   "application/json",
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// ErrNotAcceptable is returned when none of the formats offered is acceptable to the client
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// MediaType returns the media type of the format
+func (f Format) MediaType() string {
+	return formatMediaTypes[f]
+}
+
+// NegotiateFormat picks the format of the response among the offered ones, the first one being the default.
+// The `format` query parameter, e.g. `format=csv`, takes precedence over the `Accept` header.
+func NegotiateFormat(r *http.Request, offered ...Format) (Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, f := range offered {
+			if string(f) == strings.ToLower(name) {
+				return f, nil
+			}
+		}
+
+		return "", ErrNotAcceptable
+	}
+
+	// the problem media type only tells how errors are rendered, see WantsProblem
+	var ranges []mediaRange
+	for _, mr := range parseAccept(r.Header.Get("Accept")) {
+		if mr.mediaType != problemMediaType {
+			ranges = append(ranges, mr)
+		}
+	}
+
+	if len(ranges) == 0 {
+		return offered[0], nil
+	}
+
+	best, bestQuality := Format(""), 0.0
+	for _, f := range offered {
+		if q := acceptQuality(ranges, f.MediaType()); q > bestQuality {
+			best, bestQuality = f, q
+		}
+	}
+
+	if bestQuality == 0 {
+		return "", ErrNotAcceptable
+	}
+
+	return best, nil
+}
+
+// mediaRange is a media range of the `Accept` header along with its quality
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media ranges of the `Accept` header, the most specific ones first
+// so that `text/csv;q=0` overrides `*/*`
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	return ranges
+}
+
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// acceptQuality returns the quality of the most specific media range matching the media type, 0 when none does
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	for _, r := range ranges {
+		if r.mediaType == mediaType || r.mediaType == "*/*" ||
+			strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*")) {
+			return r.quality
+		}
+	}
+
+	return 0
+}
+
+// formulaPrefixes are the characters a spreadsheet reads a cell starting with as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// CSV renders the rows as a response of type csv, the header being the first row.
+// Cells a spreadsheet would read as a formula are escaped, see escapeFormula.
+func CSV(w http.ResponseWriter, code int, header []string, rows [][]string) (err error) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(code)
+
+	cw := csv.NewWriter(w)
+	if err = cw.Write(header); err != nil {
+		return
+	}
+
+	for _, row := range rows {
+		escaped := make([]string, len(row))
+		for i, cell := range row {
+			escaped[i] = escapeFormula(cell)
+		}
+
+		if err = cw.Write(escaped); err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// escapeFormula prefixes the cell with `'` when it starts like a formula, e.g. `=HYPERLINK(...)`,
+// so that spreadsheets show it as text
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// NDJSON renders each item as a JSON document on its own line, as a response of type ndjson
+func NDJSON(w http.ResponseWriter, code int, items []interface{}) (err error) {
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(true)
+
+	for _, item := range items {
+		if err = enc.Encode(item); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -8,7 +8,11 @@ import (
 
 // JSON render a generic interface as response of type json
 func JSON(w http.ResponseWriter, code int, v interface{}) (err error) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return writeJSON(w, code, "application/json; charset=utf-8", v)
+}
+
+func writeJSON(w http.ResponseWriter, code int, contentType string, v interface{}) (err error) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(code)
 
 	if v == nil || code == http.StatusNoContent {
@@ -25,17 +29,53 @@ func JSON(w http.ResponseWriter, code int, v interface{}) (err error) {
 	return
 }
 
-// JSONError render json error response
-func JSONError(w http.ResponseWriter, status int, error string, messages map[string]string) {
-	res := Response{
-		Errors:   error,
-		Messages: changeAttributeKeysInError(messages),
+// JSONError render json error response with the status of the error code,
+// as an RFC 7807 problem when the request asks for one, see WantsProblem
+func JSONError(w http.ResponseWriter, r *http.Request, code ErrorCode, messages map[string]string) {
+	// the error is rendered after the headers the request asks for a problem with
+	addVary(w.Header(), "Accept", "API-Version")
+
+	if WantsProblem(r) {
+		writeJSON(w, code.Status, problemMediaType+"; charset=utf-8", NewProblem(r, code, messages))
+		return
 	}
 
-	JSON(w, status, res)
+	res := NewResponse(code, messages)
+
+	JSON(w, code.Status, res)
 	return
 }
 
+// addVary adds the headers to the Vary header of the response, leaving out the ones it already lists
+func addVary(h http.Header, headers ...string) {
+	listed := make(map[string]bool)
+	for _, value := range h.Values("Vary") {
+		for _, header := range strings.Split(value, ",") {
+			listed[http.CanonicalHeaderKey(strings.TrimSpace(header))] = true
+		}
+	}
+
+	var missing []string
+	for _, header := range headers {
+		if !listed[http.CanonicalHeaderKey(header)] {
+			missing = append(missing, header)
+		}
+	}
+
+	if len(missing) > 0 {
+		h.Add("Vary", strings.Join(missing, ", "))
+	}
+}
+
+// NewResponse creates the error Response, keying messages by attribute name
+func NewResponse(code ErrorCode, messages map[string]string) Response {
+	return Response{
+		Errors:   code.message(),
+		Code:     code.Code,
+		Messages: changeAttributeKeysInError(messages),
+	}
+}
+
 type AttributeErrors struct{}
 
 // JSONSuccess render json success response
@@ -47,6 +87,7 @@ func JSONSuccess(w http.ResponseWriter, status int, responseBody interface{}) {
 // Response is a generic response for APIs
 type Response struct {
 	Errors   string            `json:"error"`
+	Code     string            `json:"code"`
 	Messages map[string]string `json:"messages"`
 }
 
@@ -0,0 +1,91 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	// LatencyDistribution holds the bucket boundaries of the latency views, in milliseconds
+	LatencyDistribution = view.Distribution(1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000)
+	// SizeDistribution holds the bucket boundaries of the size views, in bytes
+	SizeDistribution = view.Distribution(256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304)
+
+	routeTag  = tag.MustNewKey("http_route")
+	methodTag = tag.MustNewKey("http_method")
+	statusTag = tag.MustNewKey("http_status")
+
+	serverRequests = stats.Int64(
+		"http/server/request_count",
+		"Number of requests served",
+		stats.UnitDimensionless,
+	)
+	serverLatency = stats.Float64(
+		"http/server/latency",
+		"Time taken to serve the requests",
+		stats.UnitMilliseconds,
+	)
+	serverResponseBytes = stats.Int64(
+		"http/server/response_bytes",
+		"Size of the bodies of the responses",
+		stats.UnitBytes,
+	)
+
+	// ServerViews are the views of the requests served, registered by Metrics
+	ServerViews = []*view.View{
+		{
+			Name:        "http/server/request_count",
+			Description: "Number of requests served by route, method and status",
+			TagKeys:     []tag.Key{routeTag, methodTag, statusTag},
+			Measure:     serverRequests,
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "http/server/latency",
+			Description: "Distribution of the time taken to serve the requests by route, method and status",
+			TagKeys:     []tag.Key{routeTag, methodTag, statusTag},
+			Measure:     serverLatency,
+			Aggregation: LatencyDistribution,
+		},
+		{
+			Name:        "http/server/response_bytes",
+			Description: "Distribution of the size of the response bodies by route, method and status",
+			TagKeys:     []tag.Key{routeTag, methodTag, statusTag},
+			Measure:     serverResponseBytes,
+			Aggregation: SizeDistribution,
+		},
+	}
+)
+
+// Metrics is a middleware recording the count, latency and response size of the requests served in the ServerViews.
+// Requests are tagged by route pattern, see AccessLog, method and status.
+func Metrics(next http.Handler) http.Handler {
+	view.Register(ServerViews...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := serveObserved(next, w, r)
+
+		stats.RecordWithTags(
+			context.Background(),
+			[]tag.Mutator{
+				tag.Upsert(routeTag, req.route),
+				tag.Upsert(methodTag, r.Method),
+				tag.Upsert(statusTag, strconv.Itoa(req.status)),
+			},
+			serverRequests.M(1),
+			serverLatency.M(Milliseconds(req.latency)),
+			serverResponseBytes.M(int64(req.bytes)),
+		)
+	})
+}
+
+// Milliseconds returns the duration as fractional milliseconds, the unit of the latency views
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/http/pprof"
 
+	"github.com/scratchpay_ademola/internal/apikey"
+
 	"go.opencensus.io/zpages"
 )
 
@@ -12,32 +14,50 @@ import (
 // /ready, custom handler provided, `httputil.Ready` can be used for this
 // /debug/rpcz, RPC Stats
 // /debug/tracez, Trace Spans
+// /debug/statsz, OpenCensus views
+// /metrics, OpenCensus views and Go runtime stats in the Prometheus text format
 // /debug/pprof/, pprof
 // /debug/pprof/cmdline,  pprof
 // /debug/pprof/profile, pprof
 // /debug/pprof/symbol, pprof
 // /debug/pprof/trace, pprof
-func NewBaseMux(ready http.HandlerFunc) *http.ServeMux {
+//
+// /metrics and the /debug endpoints require the `debug` scope of the authenticator.
+// The routes record their pattern for the access log, metrics and traces, see AccessLog.
+func NewBaseMux(ready http.HandlerFunc, auth *Authenticator) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// /alive always responds with 200 OK
-	mux.HandleFunc("/alive", TextHandler(http.StatusOK, "application/json", `"OK"`))
+	mux.Handle("/alive", withRoute("/alive", TextHandler(http.StatusOK, "application/json", `"OK"`)))
 
 	// /ready is a custom handler, `httputil.Ready` can be used for this
-	mux.Handle("/health", ready)
+	mux.Handle("/health", withRoute("/health", ready))
+
+	// the debugging endpoints are served by their own mux, behind the `debug` scope
+	debug := http.NewServeMux()
+	requireDebug := auth.Require(apikey.ScopeDebug)
 
 	// zPages exposes various debugging data from OpenCensus
 	// endpoints: /debug/rpcz, /debug/tracez
 	// more info: https://opencensus.io/zpages/go/
-	zpages.Handle(mux, "/debug")
+	zpages.Handle(debug, "/debug")
+
+	// /debug/statsz renders the data of the OpenCensus views, e.g. the ServerViews
+	registerViewExporter()
+	debug.HandleFunc("/debug/statsz", statsz)
 
 	// pprof allows remote profiling
 	// more info: https://golang.org/pkg/net/http/pprof/
-	mux.HandleFunc("/debug/pprof/", pprof.Index)
-	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	debug.HandleFunc("/debug/pprof/", pprof.Index)
+	debug.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	debug.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	debug.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	debug.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.Handle("/debug/", withRoute("/debug/", requireDebug(withMuxRoutes(debug))))
+
+	// /metrics is scraped by Prometheus
+	mux.Handle("/metrics", withRoute("/metrics", requireDebug(http.HandlerFunc(prometheusMetrics))))
 
 	return mux
 }
@@ -0,0 +1,143 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// unmatchedRoute is the route of the requests no route matched, so that unknown paths don't each create
+// a series or a span name
+const unmatchedRoute = "unmatched"
+
+// observedRequest describes a served request
+type observedRequest struct {
+	// route is the pattern of the route the request was served by, see serveObserved
+	route   string
+	status  int
+	bytes   int
+	latency time.Duration
+}
+
+// serveObserved serves the request through next, recording its route pattern, status, response size and latency.
+//
+// The routing context is created up front when the request has none, the chi router the request is handed to
+// fills it in so that the route pattern can be read once the request is served. The routes of a ServeMux record
+// their pattern through withRoute, and requests no route matched, whatever their status, get unmatchedRoute,
+// including the ones a mounted router matched no route for.
+func serveObserved(next http.Handler, w http.ResponseWriter, r *http.Request) observedRequest {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		rctx = chi.NewRouteContext()
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w}
+
+	next.ServeHTTP(rec, r)
+
+	// a pattern still ending with the wildcard of a mounted router is the one of a request
+	// the mounted router matched no route for, e.g. `/v1/clinics/*` for `/v1/clinics/foo/bar`
+	route := routePattern(rctx)
+	if route == "" || strings.HasSuffix(route, "/*") {
+		route = unmatchedRoute
+	}
+
+	return observedRequest{
+		route:   route,
+		status:  rec.Status(),
+		bytes:   rec.bytes,
+		latency: time.Since(start),
+	}
+}
+
+// withRoute returns a handler recording the pattern as the route of the requests it serves, see serveObserved,
+// for the routes of a ServeMux which chi doesn't know about
+func withRoute(pattern string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setRoute(r, pattern)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// withMuxRoutes returns a handler serving the requests through the mux, recording the pattern of the route
+// matching them, see withRoute. Requests matching no route keep the route recorded so far.
+func withMuxRoutes(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			setRoute(r, pattern)
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// setRoute sets the pattern as the route of the request, replacing the one recorded so far
+func setRoute(r *http.Request, pattern string) {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		rctx.RoutePatterns = []string{pattern}
+	}
+}
+
+// routePattern returns the route pattern of the routing context, without the `/*` and duplicate slashes
+// the patterns of mounted routers are joined with, e.g. `/v1/clinics/` for `/v1/clinics/*` and `/`
+func routePattern(rctx *chi.Context) string {
+	pattern := strings.Join(rctx.RoutePatterns, "")
+
+	for strings.Contains(pattern, "/*/") {
+		pattern = strings.Replace(pattern, "/*/", "/", -1)
+	}
+
+	for strings.Contains(pattern, "//") {
+		pattern = strings.Replace(pattern, "//", "/", -1)
+	}
+
+	return pattern
+}
+
+// statusRecorder records the status and the size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int
+}
+
+// Status returns the status of the response, 200 when the handler wrote the body without a header
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+
+	return n, err
+}
+
+// Flush sends the buffered data to the client when the underlying writer supports it, e.g. for the pprof trace
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
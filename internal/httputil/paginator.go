@@ -1,12 +1,17 @@
 package httputil
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 const (
 	PaginationMaxSize = 50
+	// PaginationMaxOffset bounds the offset of the first item of a page, so that deep pages are rejected
+	// rather than overflowing the offset
+	PaginationMaxOffset = 1000000
 )
 
 type Pager struct {
@@ -15,8 +20,18 @@ type Pager struct {
 	OffSet int
 }
 
-//GetPager returns pager object containing pagination params
-func GetPager(r *http.Request) Pager {
+// PageMeta describes the page returned in a paginated response
+type PageMeta struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+//GetPager returns pager object containing pagination params,
+// it fails when the page starts beyond PaginationMaxOffset
+func GetPager(r *http.Request) (Pager, error) {
 
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
@@ -30,11 +45,107 @@ func GetPager(r *http.Request) Pager {
 		page = 1
 	}
 
+	// compared by division so that a huge page cannot overflow the offset
+	if page-1 > PaginationMaxOffset/size {
+		return Pager{}, fmt.Errorf("page must start within the first %d items", PaginationMaxOffset)
+	}
+
 	offset := (page - 1) * size
 
 	return Pager{
 		Page:   page,
 		Size:   size,
 		OffSet: offset,
+	}, nil
+}
+
+// Bounds returns the start and end indexes of the page within a result set of the given total size
+func (p Pager) Bounds(total int) (start, end int) {
+	start = p.OffSet
+	if start < 0 {
+		start = 0
+	}
+	if start > total {
+		start = total
+	}
+
+	end = start + p.Size
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+// TotalPages returns the number of pages needed to hold total items
+func (p Pager) TotalPages(total int) int {
+	return (total + p.Size - 1) / p.Size
+}
+
+// Meta returns the page metadata for a result set of the given total size
+func (p Pager) Meta(total int) PageMeta {
+	return PageMeta{
+		Total:      total,
+		Page:       p.Page,
+		Size:       p.Size,
+		TotalPages: p.TotalPages(total),
+	}
+}
+
+// SetPageHeaders writes the `X-Total-Count` header and the RFC 5988 `Link` header
+// with the next, prev, first and last relations for the page
+func SetPageHeaders(w http.ResponseWriter, r *http.Request, p Pager, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	last := p.TotalPages(total)
+	if last < 1 {
+		last = 1
+	}
+
+	links := make([]string, 0, 4)
+	if p.Page < last {
+		links = append(links, pageLink(r, p.Page+1, p.Size, "next"))
+	}
+
+	if p.Page > 1 {
+		prev := p.Page - 1
+		if prev > last {
+			prev = last
+		}
+		links = append(links, pageLink(r, prev, p.Size, "prev"))
+	}
+
+	links = append(links, pageLink(r, 1, p.Size, "first"), pageLink(r, last, p.Size, "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// SetCursorHeaders writes the `X-Total-Count` header and, when there is a next page,
+// the RFC 5988 `Link` header pointing to it through its cursor
+func SetCursorHeaders(w http.ResponseWriter, r *http.Request, total int, next string) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	if next == "" {
+		return
 	}
+
+	query := r.URL.Query()
+	query.Del("page")
+	query.Set("cursor", next)
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, u.RequestURI()))
+}
+
+func pageLink(r *http.Request, page, size int, rel string) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
 }
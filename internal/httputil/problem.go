@@ -0,0 +1,68 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ProblemAPIVersion is the first version of the API, requested with the `API-Version` header,
+// rendering errors as RFC 7807 problems
+const ProblemAPIVersion = 2
+
+// problemMediaType is the media type of problems
+const problemMediaType = "application/problem+json"
+
+// Problem is an RFC 7807 `application/problem+json` error response. Its type points to the error code in the
+// catalog, see ErrorCatalogPath, and the code and retryable flag are repeated as extensions.
+// Errors holds the messages of the invalid attributes keyed by attribute name.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	Retryable bool              `json:"retryable"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// WantsProblem reports whether errors are rendered as problems for the request, either because it accepts
+// `application/problem+json` or because it asks for an API version from ProblemAPIVersion on.
+// Other requests keep the `{"error", "messages"}` response of the first version of the API.
+func WantsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	if version, err := strconv.Atoi(r.Header.Get("API-Version")); err == nil && version >= ProblemAPIVersion {
+		return true
+	}
+
+	for _, mr := range parseAccept(r.Header.Get("Accept")) {
+		if mr.mediaType == problemMediaType && mr.quality > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewProblem creates the problem of a request, the title being the default message of the error code
+// and the detail the message of the response
+func NewProblem(r *http.Request, code ErrorCode, messages map[string]string) Problem {
+	p := Problem{
+		Type:      ErrorCatalogPath + "#" + code.Code,
+		Title:     code.Message,
+		Status:    code.Status,
+		Detail:    code.message(),
+		Code:      code.Code,
+		Retryable: code.Retryable,
+		Errors:    changeAttributeKeysInError(messages),
+	}
+
+	if r != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+
+	return p
+}
@@ -0,0 +1,152 @@
+package httputil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/stats/view"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusMetrics renders the data of the registered OpenCensus views, and the Go runtime stats,
+// in the Prometheus text exposition format. A view is named after its name with every character other than letters,
+// digits and `_` replaced by `_`, e.g. `http_server_request_count` for `http/server/request_count`.
+//
+// Counts are rendered as counters, last values as gauges, distributions as histograms and sums as untyped metrics.
+// Views show up from the first reporting period after their registration, see statsz.
+func prometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+
+	for _, vd := range views.data() {
+		writePrometheusView(w, vd)
+	}
+
+	writeRuntimeMetrics(w)
+}
+
+func writePrometheusView(w io.Writer, vd *view.Data) {
+	name := prometheusName(vd.View.Name)
+
+	metricType := "untyped"
+	switch vd.View.Aggregation.Type {
+	case view.AggTypeCount:
+		metricType = "counter"
+	case view.AggTypeLastValue:
+		metricType = "gauge"
+	case view.AggTypeDistribution:
+		metricType = "histogram"
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(vd.View.Description))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+
+	lines := make([]string, 0, len(vd.Rows))
+	for _, row := range vd.Rows {
+		labels := make([]string, len(row.Tags))
+		for i, t := range row.Tags {
+			labels[i] = prometheusName(t.Key.Name()) + `="` + escapeLabel(t.Value) + `"`
+		}
+
+		switch data := row.Data.(type) {
+		case *view.CountData:
+			lines = append(lines, sample(name, labels, float64(data.Value)))
+		case *view.SumData:
+			lines = append(lines, sample(name, labels, data.Value))
+		case *view.LastValueData:
+			lines = append(lines, sample(name, labels, data.Value))
+		case *view.DistributionData:
+			lines = append(lines, histogram(name, labels, vd.View.Aggregation.Buckets, data))
+		}
+	}
+
+	sort.Strings(lines)
+	for _, line := range lines {
+		io.WriteString(w, line)
+	}
+}
+
+// histogram renders the cumulative buckets, the sum and the count of a distribution
+func histogram(name string, labels []string, bounds []float64, data *view.DistributionData) string {
+	var b strings.Builder
+
+	cumulative := int64(0)
+	for i, bound := range bounds {
+		cumulative += data.CountPerBucket[i]
+		b.WriteString(sample(name+"_bucket", append(labels[:len(labels):len(labels)], `le="`+formatFloat(bound)+`"`), float64(cumulative)))
+	}
+
+	b.WriteString(sample(name+"_bucket", append(labels[:len(labels):len(labels)], `le="+Inf"`), float64(data.Count)))
+	b.WriteString(sample(name+"_sum", labels, data.Mean*float64(data.Count)))
+	b.WriteString(sample(name+"_count", labels, float64(data.Count)))
+
+	return b.String()
+}
+
+func writeRuntimeMetrics(w io.Writer) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	// the pause of the last garbage collection, in the circular buffer of the most recent pauses
+	lastPause := uint64(0)
+	if mem.NumGC > 0 {
+		lastPause = mem.PauseNs[(mem.NumGC+255)%256]
+	}
+
+	metrics := []struct {
+		name, help, metricType string
+		value                  float64
+	}{
+		{"go_goroutines", "Number of goroutines that currently exist.", "gauge", float64(runtime.NumGoroutine())},
+		{"go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", "gauge", float64(mem.HeapAlloc)},
+		{"go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", "gauge", float64(mem.HeapInuse)},
+		{"go_memstats_heap_objects", "Number of allocated objects.", "gauge", float64(mem.HeapObjects)},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from system.", "gauge", float64(mem.Sys)},
+		{"go_gc_cycles_total", "Number of completed GC cycles.", "counter", float64(mem.NumGC)},
+		{"go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", "counter", float64(mem.PauseTotalNs) / 1e9},
+		{"go_gc_last_pause_seconds", "Duration of the last GC stop-the-world pause.", "gauge", float64(lastPause) / 1e9},
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.metricType)
+		io.WriteString(w, sample(m.name, nil, m.value))
+	}
+}
+
+func sample(name string, labels []string, value float64) string {
+	if len(labels) == 0 {
+		return fmt.Sprintf("%s %s\n", name, formatFloat(value))
+	}
+
+	return fmt.Sprintf("%s{%s} %s\n", name, strings.Join(labels, ","), formatFloat(value))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// prometheusName replaces the characters not allowed in Prometheus metric and label names by `_`
+func prometheusName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			b[i] = '_'
+		}
+	}
+
+	return string(b)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,95 @@
+package httputil
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	registerViewExporter()
+	view.SetReportingPeriod(10 * time.Millisecond)
+	defer view.SetReportingPeriod(0)
+
+	labelTag := tag.MustNewKey("test.label")
+	waits := stats.Float64("clinic_test/wait", "Test wait", stats.UnitMilliseconds)
+	waitViews := []*view.View{
+		{
+			Name:        "clinic_test/wait_count",
+			Description: "Number of \"waits\"",
+			TagKeys:     []tag.Key{labelTag},
+			Measure:     waits,
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "clinic_test/wait",
+			Description: "Distribution of the waits",
+			Measure:     waits,
+			Aggregation: view.Distribution(1, 10),
+		},
+	}
+	assert.NoError(t, view.Register(waitViews...))
+	defer view.Unregister(waitViews...)
+
+	for _, wait := range []float64{0.5, 5, 50} {
+		stats.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(labelTag, "say \"hi\"\\")}, waits.M(wait))
+	}
+
+	want := []string{
+		"# HELP clinic_test_wait_count Number of \"waits\"\n" +
+			"# TYPE clinic_test_wait_count counter\n" +
+			"clinic_test_wait_count{test_label=\"say \\\"hi\\\"\\\\\"} 3\n",
+		"# HELP clinic_test_wait Distribution of the waits\n" +
+			"# TYPE clinic_test_wait histogram\n" +
+			"clinic_test_wait_bucket{le=\"1\"} 1\n" +
+			"clinic_test_wait_bucket{le=\"10\"} 2\n" +
+			"clinic_test_wait_bucket{le=\"+Inf\"} 3\n" +
+			"clinic_test_wait_sum 55.5\n" +
+			"clinic_test_wait_count 3\n",
+		"# TYPE go_goroutines gauge\ngo_goroutines ",
+		"# TYPE go_memstats_heap_alloc_bytes gauge\n",
+		"# TYPE go_gc_pause_seconds_total counter\n",
+	}
+
+	assert.Eventually(t, func() bool {
+		response := httptest.NewRecorder()
+		prometheusMetrics(response, httptest.NewRequest(http.MethodGet, "http://www.test.com/metrics", nil))
+
+		b, _ := ioutil.ReadAll(response.Body)
+		body := string(b)
+
+		for _, part := range want {
+			if !strings.Contains(body, part) {
+				return false
+			}
+		}
+
+		return response.Header().Get("Content-Type") == prometheusContentType
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPrometheusName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "http/server/request_count", want: "http_server_request_count"},
+		{name: "clinic.cache-hits", want: "clinic_cache_hits"},
+		{name: "2xx/responses", want: "_xx_responses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, prometheusName(tt.name))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package httputil
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/apikey"
+)
+
+// bucketSweepInterval is how often the buckets refilled since their last request are dropped
+const bucketSweepInterval = time.Minute
+
+var codeRateLimited = NewErrorCode("RATE_LIMITED", http.StatusTooManyRequests, true, "too many requests")
+
+// RateLimit allows a client Requests requests per Period, the requests left being refilled continuously
+// along the period rather than at its end
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseRateLimit parses a rate limit given as `<requests>/<period>`, e.g. `60/1m` or `10/30s`
+func ParseRateLimit(s string) (RateLimit, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", s)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests < 1 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, the requests should be a positive integer", s)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, the period should be a positive duration", s)
+	}
+
+	return RateLimit{Requests: requests, Period: period}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the rate limit with ParseRateLimit
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	limit, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = limit
+
+	return nil
+}
+
+// rate is the number of requests refilled per second
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// ClientKey identifies the client of a request by the ID of its API key, see Authenticator.Authenticate,
+// or else by its IP address
+func ClientKey(r *http.Request) string {
+	if key, ok := apikey.From(r.Context()); ok {
+		return "key:" + key.ID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// bucket holds the requests left to a client on a route, as of its last request
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits the requests of each client on the routes with a rate limit, through token buckets.
+// Clients are identified by ClientKey, and each route pattern has its own buckets.
+type RateLimiter struct {
+	limits map[string]RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a RateLimiter limiting the requests of the routes, keyed by route pattern,
+// e.g. `/v1/clinics/search`
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// take takes a request from the bucket of the client on the route, it returns whether the request is allowed,
+// the requests left and how long until the next request is allowed, or the bucket is full when it is
+func (l *RateLimiter) take(route, client string, limit RateLimit) (allowed bool, remaining int, retry, reset time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	capacity, rate := float64(limit.Requests), limit.rate()
+
+	b, ok := l.buckets[route+" "+client]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[route+" "+client] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	allowed = b.tokens >= 1
+	if allowed {
+		b.tokens--
+	} else {
+		retry = seconds((1 - b.tokens) / rate)
+	}
+
+	return allowed, int(b.tokens), retry, seconds((capacity - b.tokens) / rate)
+}
+
+// sweep drops the buckets refilled since their last request, a new bucket being full anyway
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		route := key[:strings.Index(key, " ")]
+		limit := l.limits[route]
+
+		if b.tokens+now.Sub(b.last).Seconds()*limit.rate() >= float64(limit.Requests) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Handler is a middleware limiting the requests of the route they are served by, it has to be used within
+// the chi router matching the route, e.g. through chi.Router.With or Group, for the route pattern to be known.
+//
+// The responses of the limited routes carry the `RateLimit-Limit`, `RateLimit-Remaining`, `RateLimit-Reset`
+// and `RateLimit-Policy` headers. Requests over the limit are rejected with a 429 error and a `Retry-After` header.
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := routePattern(rctx)
+
+		limit, ok := l.limits[route]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, remaining, retry, reset := l.take(route, ClientKey(r), limit)
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(int(reset/time.Second)))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(math.Ceil(limit.Period.Seconds()))))
+
+		if !allowed {
+			h.Set("Retry-After", strconv.Itoa(int(retry/time.Second)))
+			JSONError(w, r, codeRateLimited, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// seconds returns the duration of s seconds rounded up to the second, as clients are told whole seconds
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,120 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/apikey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		limit RateLimit
+		err   bool
+	}{
+		{value: "60/1m", limit: RateLimit{Requests: 60, Period: time.Minute}},
+		{value: "10/30s", limit: RateLimit{Requests: 10, Period: 30 * time.Second}},
+		{value: "60", err: true},
+		{value: "0/1m", err: true},
+		{value: "ten/1m", err: true},
+		{value: "10/0s", err: true},
+		{value: "10/minute", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			limit, err := ParseRateLimit(tt.value)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.limit, limit)
+		})
+	}
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	limiter := NewRateLimiter(nil)
+	limit := RateLimit{Requests: 2, Period: time.Hour}
+
+	for i, want := range []int{1, 0} {
+		allowed, remaining, retry, _ := limiter.take("/v1/clinics/search", "ip:192.0.2.1", limit)
+		assert.True(t, allowed, "request %d", i)
+		assert.Equal(t, want, remaining)
+		assert.Zero(t, retry)
+	}
+
+	allowed, remaining, retry, reset := limiter.take("/v1/clinics/search", "ip:192.0.2.1", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 0, remaining)
+	assert.True(t, retry > 0 && retry <= 30*time.Minute, "retry %s", retry)
+	assert.True(t, reset > 30*time.Minute && reset <= time.Hour, "reset %s", reset)
+
+	// each client and route has its own bucket
+	allowed, _, _, _ = limiter.take("/v1/clinics/search", "ip:192.0.2.2", limit)
+	assert.True(t, allowed)
+	allowed, _, _, _ = limiter.take("/v1/clinics/", "ip:192.0.2.1", limit)
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	limiter := NewRateLimiter(nil)
+	limit := RateLimit{Requests: 1, Period: 20 * time.Millisecond}
+
+	allowed, _, _, _ := limiter.take("/v1/clinics/search", "key:acme", limit)
+	assert.True(t, allowed)
+
+	allowed, _, _, _ = limiter.take("/v1/clinics/search", "key:acme", limit)
+	assert.False(t, allowed)
+
+	time.Sleep(2 * limit.Period)
+
+	allowed, _, _, _ = limiter.take("/v1/clinics/search", "key:acme", limit)
+	assert.True(t, allowed)
+}
+
+func TestClientKey(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+	assert.Equal(t, "ip:192.0.2.1", ClientKey(request))
+
+	request.RemoteAddr = "192.0.2.1"
+	assert.Equal(t, "ip:192.0.2.1", ClientKey(request))
+
+	request = request.WithContext(apikey.With(request.Context(), apikey.Key{ID: "acme"}))
+	assert.Equal(t, "key:acme", ClientKey(request))
+}
+
+func TestRateLimiterProblem(t *testing.T) {
+	limiter := NewRateLimiter(map[string]RateLimit{
+		"/v1/errors": {Requests: 1, Period: time.Minute},
+	})
+
+	routes := chi.NewRouter()
+	routes.With(limiter.Handler).Get("/v1/errors", ErrorCatalogHandler())
+
+	var response *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/errors", nil)
+		request.Header.Set("Accept", "application/problem+json")
+
+		response = httptest.NewRecorder()
+		routes.ServeHTTP(response, request)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.Equal(t, "60", response.Header().Get("Retry-After"))
+
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&problem))
+	assert.Equal(t, "RATE_LIMITED", problem.Code)
+	assert.True(t, problem.Retryable)
+}
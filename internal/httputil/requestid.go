@@ -0,0 +1,23 @@
+package httputil
+
+import (
+	"net/http"
+
+	"github.com/scratchpay_ademola/internal/requestid"
+)
+
+// RequestID is a middleware identifying each request by the `X-Request-ID` header sent by the client,
+// or by a generated ID when the header is missing or invalid. The ID is stored in the context of the request,
+// see requestid.From, and echoed in the `X-Request-ID` header of the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+
+		next.ServeHTTP(w, r.WithContext(requestid.With(r.Context(), id)))
+	})
+}
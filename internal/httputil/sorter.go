@@ -0,0 +1,51 @@
+package httputil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SortField is a single key of a sort specification, ordered descending when Desc is set
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// String returns the field in its query parameter form, e.g. `-name`
+func (s SortField) String() string {
+	if s.Desc {
+		return "-" + s.Name
+	}
+
+	return s.Name
+}
+
+// GetSort returns the sort fields of the `sort` query parameter, e.g. `sort=state,-name`
+func GetSort(r *http.Request) []SortField {
+	return ParseSort(r.URL.Query().Get("sort"))
+}
+
+// ParseSort parses a comma separated sort specification,
+// a field prefixed with `-` is sorted descending and one prefixed with `+` ascending
+func ParseSort(spec string) []SortField {
+	var fields []SortField
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Name: part}
+		switch part[0] {
+		case '-':
+			field = SortField{Name: part[1:], Desc: true}
+		case '+':
+			field = SortField{Name: part[1:]}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
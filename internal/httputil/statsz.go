@@ -0,0 +1,116 @@
+package httputil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+)
+
+// viewExporter discovers the registered views, they are exported every reporting period once registered
+type viewExporter struct {
+	mu    sync.RWMutex
+	views map[string]*view.View
+}
+
+var (
+	views             = &viewExporter{views: make(map[string]*view.View)}
+	registerViewsOnce sync.Once
+)
+
+// ExportView implements view.Exporter
+func (e *viewExporter) ExportView(vd *view.Data) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.views[vd.View.Name] = vd.View
+}
+
+// data returns the current data of the views discovered, ordered by name
+func (e *viewExporter) data() []*view.Data {
+	e.mu.RLock()
+	names := make([]string, 0, len(e.views))
+	for name := range e.views {
+		names = append(names, name)
+	}
+	e.mu.RUnlock()
+
+	sort.Strings(names)
+
+	data := make([]*view.Data, 0, len(names))
+	for _, name := range names {
+		v := view.Find(name)
+		if v == nil {
+			// the view was unregistered since
+			continue
+		}
+
+		rows, err := view.RetrieveData(name)
+		if err != nil {
+			continue
+		}
+
+		data = append(data, &view.Data{View: v, Rows: rows})
+	}
+
+	return data
+}
+
+// registerViewExporter registers the exporter discovering the views, once
+func registerViewExporter() {
+	registerViewsOnce.Do(func() {
+		view.RegisterExporter(views)
+	})
+}
+
+// statsz renders the data of the registered OpenCensus views as text, one line per view and per row.
+// Views show up from the first reporting period after their registration.
+func statsz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	for _, vd := range views.data() {
+		fmt.Fprintf(w, "%s: %s (%s, %s)\n", vd.View.Name, vd.View.Description, vd.View.Aggregation.Type, vd.View.Measure.Unit())
+
+		rows := make([]string, 0, len(vd.Rows))
+		for _, row := range vd.Rows {
+			rows = append(rows, fmt.Sprintf("  %s %s\n", formatTags(row), formatAggregation(row.Data)))
+		}
+
+		sort.Strings(rows)
+		for _, row := range rows {
+			io.WriteString(w, row)
+		}
+	}
+}
+
+func formatTags(row *view.Row) string {
+	if len(row.Tags) == 0 {
+		return "-"
+	}
+
+	tags := make([]string, len(row.Tags))
+	for i, t := range row.Tags {
+		tags[i] = fmt.Sprintf("%s=%q", t.Key.Name(), t.Value)
+	}
+
+	return strings.Join(tags, " ")
+}
+
+func formatAggregation(data view.AggregationData) string {
+	switch d := data.(type) {
+	case *view.CountData:
+		return fmt.Sprintf("count=%d", d.Value)
+	case *view.SumData:
+		return fmt.Sprintf("sum=%g", d.Value)
+	case *view.LastValueData:
+		return fmt.Sprintf("last=%g", d.Value)
+	case *view.DistributionData:
+		return fmt.Sprintf("count=%d mean=%.3f min=%g max=%g buckets=%v", d.Count, d.Mean, d.Min, d.Max, d.CountPerBucket)
+	default:
+		return fmt.Sprint(data)
+	}
+}
@@ -0,0 +1,45 @@
+package httputil
+
+import (
+	"net/http"
+
+	"github.com/scratchpay_ademola/internal/requestid"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+)
+
+// TraceFormat propagates the trace context through the W3C `traceparent` and `tracestate` headers
+var TraceFormat = &tracecontext.HTTPFormat{}
+
+// Tracing is a middleware serving each request within a server span, continuing the trace of the `traceparent`
+// header when the client sent one. The span is named after the route pattern, see AccessLog, and carries
+// the method, route, status, user agent and request ID of the request.
+//
+// Whether a trace is sampled follows the sampled flag of the `traceparent` header, or else the default sampler
+// of the trace config, see trace.ApplyConfig.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var span *trace.Span
+		if parent, ok := TraceFormat.SpanContextFromRequest(r); ok {
+			ctx, span = trace.StartSpanWithRemoteParent(ctx, r.URL.Path, parent, trace.WithSpanKind(trace.SpanKindServer))
+		} else {
+			ctx, span = trace.StartSpan(ctx, r.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+		}
+		defer span.End()
+
+		req := serveObserved(next, w, r.WithContext(ctx))
+
+		span.SetName(req.route)
+		span.AddAttributes(
+			trace.StringAttribute(ochttp.MethodAttribute, r.Method),
+			trace.StringAttribute("http.route", req.route),
+			trace.Int64Attribute(ochttp.StatusCodeAttribute, int64(req.status)),
+			trace.StringAttribute(ochttp.UserAgentAttribute, r.UserAgent()),
+			trace.StringAttribute("request_id", requestid.From(ctx)),
+		)
+		span.SetStatus(ochttp.TraceStatus(req.status, http.StatusText(req.status)))
+	})
+}
@@ -3,11 +3,28 @@ package logger
 import (
 	"context"
 
+	"github.com/scratchpay_ademola/internal/requestid"
 	"go.uber.org/zap"
 )
 
+type fieldsKey struct{}
+
+// WithFields returns a copy of the context holding the fields along with the ones it already holds,
+// the loggers created from the context carry them, see From
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	held := contextFields(ctx)
+
+	return context.WithValue(ctx, fieldsKey{}, append(held[:len(held):len(held)], fields...))
+}
+
+func contextFields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
 // From creates a logger from the current context
-// adds contextual attributes if possible
+// adds contextual attributes if possible, the `request_id` of the request being handled
+// and the fields added to the context with WithFields
 func From(ctx context.Context, options ...Option) *zap.Logger {
 	logger := zap.L()
 
@@ -15,7 +32,14 @@ func From(ctx context.Context, options ...Option) *zap.Logger {
 		logger = option(logger)
 	}
 
-	fields := make([]zap.Field, 0, 2)
+	held := contextFields(ctx)
+	fields := make([]zap.Field, 0, 1+len(held))
+
+	if id := requestid.From(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+
+	fields = append(fields, held...)
 
 	return logger.With(fields...)
 }
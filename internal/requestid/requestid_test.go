@@ -0,0 +1,43 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "printable id", id: "client-id_42", want: true},
+		{name: "longest id", id: strings.Repeat("a", 128), want: true},
+		{name: "empty id", id: ""},
+		{name: "id too long", id: strings.Repeat("a", 129)},
+		{name: "spaces", id: "forged id"},
+		{name: "line breaks", id: "id\nforged: true"},
+		{name: "non ASCII characters", id: "clínica"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Valid(tt.id))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Regexp(t, `^[0-9a-f]{32}$`, id)
+	assert.True(t, Valid(id))
+	assert.NotEqual(t, id, New())
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, From(context.Background()))
+	assert.Equal(t, "client-id_42", From(With(context.Background(), "client-id_42")))
+}
@@ -0,0 +1,111 @@
+package spelling
+
+import (
+	"sort"
+	"strings"
+)
+
+// Distance returns the edit distance between a and b ignoring case, counting insertions, deletions,
+// substitutions and transpositions of adjacent characters as one edit each
+func Distance(a, b string) int {
+	s, t := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+
+	// d[i][j] is the distance between the first i runes of s and the first j runes of t
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(s)][len(t)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// MaxDistance is the number of edits a word can be away from a suggestion, growing with the length of the word
+func MaxDistance(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 4:
+		return 1
+	case n <= 8:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Suggest returns up to limit words of the vocabulary close to the word, the closest first.
+// Words equal to the word ignoring case are not suggested.
+func Suggest(word string, vocabulary []string, limit int) []string {
+	type candidate struct {
+		word     string
+		distance int
+	}
+
+	maxDistance := MaxDistance(word)
+
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, v := range vocabulary {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+
+		// words whose length differs by more than the distance need more edits, skipping them spares the distance
+		if diff := len([]rune(word)) - len([]rune(v)); diff > maxDistance || -diff > maxDistance {
+			continue
+		}
+
+		if d := Distance(word, v); d > 0 && d <= maxDistance {
+			candidates = append(candidates, candidate{v, d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+
+		return candidates[i].word < candidates[j].word
+	})
+
+	var suggestions []string
+	for _, c := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+
+		suggestions = append(suggestions, c.word)
+	}
+
+	return suggestions
+}
@@ -0,0 +1,65 @@
+package spelling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "health", b: "health", want: 0},
+		{a: "Health", b: "HEALTH", want: 0},
+		{a: "heatlh", b: "health", want: 1},
+		{a: "helth", b: "health", want: 1},
+		{a: "healths", b: "health", want: 1},
+		{a: "hualth", b: "health", want: 1},
+		{a: "natonal", b: "national", want: 1},
+		{a: "", b: "vet", want: 3},
+		{a: "clínica", b: "clinica", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, Distance(tt.a, tt.b))
+			assert.Equal(t, tt.want, Distance(tt.b, tt.a))
+		})
+	}
+}
+
+func TestMaxDistance(t *testing.T) {
+	assert.Equal(t, 1, MaxDistance("vet"))
+	assert.Equal(t, 1, MaxDistance("good"))
+	assert.Equal(t, 2, MaxDistance("health"))
+	assert.Equal(t, 2, MaxDistance("clínicas"))
+	assert.Equal(t, 3, MaxDistance("veterinary"))
+}
+
+func TestSuggest(t *testing.T) {
+	vocabulary := []string{"Health", "Wealth", "Home", "National", "Veterinary", "Health"}
+
+	tests := []struct {
+		name  string
+		word  string
+		limit int
+		want  []string
+	}{
+		{name: "closest first", word: "healt", limit: 3, want: []string{"Health", "Wealth"}},
+		{name: "up to the limit", word: "healt", limit: 1, want: []string{"Health"}},
+		{name: "ties ordered by word", word: "xealth", limit: 3, want: []string{"Health", "Wealth"}},
+		{name: "distance growing with the length", word: "vetrinery", limit: 1, want: []string{"Veterinary"}},
+		{name: "a typo in a short word", word: "hme", limit: 1, want: []string{"Home"}},
+		{name: "nothing close", word: "dental", limit: 3},
+		{name: "equal words are not suggested", word: "HOME", limit: 3},
+		{name: "lengths too different", word: "nat", limit: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Suggest(tt.word, vocabulary, tt.limit))
+		})
+	}
+}
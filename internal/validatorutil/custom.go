@@ -0,0 +1,110 @@
+package validatorutil
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/scratchpay_ademola/internal/geo"
+	"github.com/scratchpay_ademola/internal/spelling"
+)
+
+// clockRegex matches a `HH:MM` time of the day, `24:00` is accepted as the end of the day
+var clockRegex = regexp.MustCompile(`^(([01][0-9]|2[0-3]):[0-5][0-9]|24:00)$`)
+
+// customValidation is a validation tag along with the message of its translated errors,
+// {0} is the field and {1} the param of the tag. The message is completed with the value suggest returns
+// for the invalid value, when it returns one.
+type customValidation struct {
+	tag     string
+	fn      validator.Func
+	message string
+	suggest func(value string) string
+}
+
+var customValidations = []customValidation{
+	{"clock", isClock, "{0} must be a time in the HH:MM format", nil},
+	{"beforetime", isBeforeTime, "{0} must be before {1}", nil},
+	{"usstate", isUSState, "{0} must be a known state code or name", suggestUSState},
+}
+
+func registerCustomValidations(validate *validator.Validate, trans ut.Translator) {
+	for _, cv := range customValidations {
+		cv := cv
+
+		validate.RegisterValidation(cv.tag, cv.fn)
+		validate.RegisterTranslation(cv.tag, trans, func(ut ut.Translator) error {
+			return ut.Add(cv.tag, cv.message, true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T(cv.tag, fe.Field(), fe.Param())
+
+			if cv.suggest != nil {
+				if suggestion := cv.suggest(fmt.Sprint(fe.Value())); suggestion != "" {
+					t += fmt.Sprintf(", did you mean %s?", suggestion)
+				}
+			}
+
+			return t
+		})
+	}
+}
+
+// isClock validates the field is a `HH:MM` time
+func isClock(fl validator.FieldLevel) bool {
+	return clockRegex.MatchString(fl.Field().String())
+}
+
+// isBeforeTime validates the `HH:MM` time of the field is before the one of the field with the json name of the param,
+// it passes when either time is missing or invalid as those are reported by their own tags
+func isBeforeTime(fl validator.FieldLevel) bool {
+	other, ok := fieldByJSONName(fl.Parent(), fl.Param())
+	if !ok || other.Kind() != reflect.String {
+		return false
+	}
+
+	from, to := fl.Field().String(), other.String()
+	if !clockRegex.MatchString(from) || !clockRegex.MatchString(to) {
+		return true
+	}
+
+	// times in the HH:MM format sort lexically
+	return from < to
+}
+
+// isUSState validates the field is the code or name of a known state
+func isUSState(fl validator.FieldLevel) bool {
+	_, ok := geo.LookupState(fl.Field().String())
+	return ok
+}
+
+// suggestUSState returns the name of the state closest to the misspelled value
+func suggestUSState(value string) string {
+	names := make([]string, len(geo.States))
+	for i, state := range geo.States {
+		names[i] = state.Name
+	}
+
+	if suggestions := spelling.Suggest(value, names, 1); len(suggestions) > 0 {
+		return suggestions[0]
+	}
+
+	return ""
+}
+
+func fieldByJSONName(parent reflect.Value, name string) (reflect.Value, bool) {
+	parent = reflect.Indirect(parent)
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for i := 0; i < parent.NumField(); i++ {
+		if strings.SplitN(parent.Type().Field(i).Tag.Get("json"), ",", 2)[0] == name {
+			return parent.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
@@ -3,6 +3,7 @@ package validatorutil
 import (
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -10,18 +11,29 @@ import (
 	en_translations "github.com/go-playground/validator/v10/translations/en"
 )
 
-var uni *ut.UniversalTranslator
-var trans ut.Translator
+var (
+	setupOnce sync.Once
+	validate  *validator.Validate
+	trans     ut.Translator
+)
 
+// GetValidator returns the validator, built along with its translator on the first call and shared afterwards,
+// a validator being safe for concurrent use
 func GetValidator() *validator.Validate {
+	setupOnce.Do(setup)
+
+	return validate
+}
+
+func setup() {
 	en := en.New()
-	uni = ut.New(en, en)
+	uni := ut.New(en, en)
 
 	// this is usually know or extracted from http 'Accept-Language' header
 	// also see uni.FindTranslator(...)
 	trans, _ = uni.GetTranslator("en")
 
-	validate := validator.New()
+	validate = validator.New()
 	en_translations.RegisterDefaultTranslations(validate, trans)
 
 	validate.SetTagName("validate")
@@ -31,10 +43,12 @@ func GetValidator() *validator.Validate {
 		return name
 	})
 
-	return validate
+	registerCustomValidations(validate, trans)
 }
 
 func GetTranslatedErrors(err error) map[string]string {
+	setupOnce.Do(setup)
+
 	errs := err.(validator.ValidationErrors)
 	errorMessages := errs.Translate(trans)
 	return errorMessages
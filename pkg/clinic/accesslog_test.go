@@ -0,0 +1,84 @@
+package clinic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/scratchpay_ademola/internal/requestid"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		sampleRate map[string]float64
+		wantLogged bool
+		wantRoute  string
+		wantStatus int
+	}{
+		{name: "logs the route pattern", url: "/v1/clinics/?size=1", wantLogged: true, wantRoute: "/v1/clinics/", wantStatus: http.StatusOK},
+		{name: "logs failed requests", url: "/v1/clinics/?sort=zip", wantLogged: true, wantRoute: "/v1/clinics/", wantStatus: http.StatusBadRequest},
+		{name: "logs the pattern of the routes outside of the router", url: "/alive", wantLogged: true, wantRoute: "/alive", wantStatus: http.StatusOK},
+		{name: "logs unknown paths as unmatched", url: "/v1/unknown/42", wantLogged: true, wantRoute: "unmatched", wantStatus: http.StatusNotFound},
+		{name: "excludes the health endpoint", url: "/health", wantLogged: false},
+		{name: "samples out the route", url: "/v1/clinics/", sampleRate: map[string]float64{"/v1/clinics/": 0}, wantLogged: false},
+		{name: "samples in the route", url: "/v1/clinics/", sampleRate: map[string]float64{"/v1/clinics/": 1}, wantLogged: true, wantRoute: "/v1/clinics/", wantStatus: http.StatusOK},
+		{name: "always logs failed requests of a sampled route", url: "/v1/clinics/?sort=zip", sampleRate: map[string]float64{"/v1/clinics/": 0}, wantLogged: true, wantRoute: "/v1/clinics/", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil).Maybe()
+
+			routes := chi.NewRouter()
+			routes.Route("/v1/clinics", func(r chi.Router) {
+				r.Get("/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+			})
+
+			mux := httputil.NewBaseMux(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`), httputil.NewAuthenticator(nil, false))
+			mux.Handle("/", routes)
+
+			handler := httputil.RequestID(httputil.AccessLog(zap.New(core), httputil.AccessLogConfig{
+				Exclude:     []string{"/health", "/debug/"},
+				SampleRates: tt.sampleRate,
+			})(mux))
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com"+tt.url, nil)
+			request.Header.Set("User-Agent", "clinic-test")
+			request.Header.Set(requestid.Header, "abc-123")
+
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			if !tt.wantLogged {
+				assert.Zero(t, logs.Len())
+				return
+			}
+
+			entries := logs.All()
+			assert.Len(t, entries, 1)
+
+			fields := entries[0].ContextMap()
+			assert.Equal(t, "request served", entries[0].Message)
+			assert.Equal(t, "GET", fields["method"])
+			assert.Equal(t, request.URL.Path, fields["path"])
+			assert.Equal(t, tt.wantRoute, fields["route"])
+			assert.Equal(t, int64(tt.wantStatus), fields["status"])
+			assert.Equal(t, int64(response.Body.Len()), fields["bytes"])
+			assert.Equal(t, "192.0.2.1:1234", fields["remote_addr"])
+			assert.Equal(t, "clinic-test", fields["user_agent"])
+			assert.Equal(t, "abc-123", fields["request_id"])
+			assert.Contains(t, fields, "latency")
+		})
+	}
+}
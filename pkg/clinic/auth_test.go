@@ -0,0 +1,163 @@
+package clinic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/apikey"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAuthentication(t *testing.T) {
+	keys, err := apikey.Parse(strings.NewReader(strings.Join([]string{
+		"reader " + apikey.Hash("reader-secret") + " clinics:read",
+		"admin " + apikey.Hash("admin-secret") + " clinics:admin",
+		"ops " + apikey.Hash("ops-secret") + " debug",
+	}, ";")))
+	assert.NoError(t, err)
+
+	auth := httputil.NewAuthenticator(keys, false)
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil).Maybe()
+
+	routes := chi.NewRouter()
+	routes.Route("/v1/clinics", func(r chi.Router) {
+		r = r.With(auth.Require(apikey.ScopeClinicsRead))
+
+		r.Get("/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+		r.With(auth.Require(apikey.ScopeClinicsAdmin)).Post("/synonyms/reload", ReloadSynonyms(testSynonyms))
+	})
+	routes.Get(httputil.ErrorCatalogPath, httputil.ErrorCatalogHandler())
+
+	mux := httputil.NewBaseMux(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`), auth)
+	mux.Handle("/", routes)
+
+	core, logs := observer.New(zap.InfoLevel)
+	handler := auth.Authenticate(httputil.AccessLog(zap.New(core), httputil.AccessLogConfig{})(mux))
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		header     string
+		value      string
+		wantStatus int
+		wantCode   string
+		wantKey    string
+		wantRoute  string
+	}{
+		{name: "rejects requests without a key", url: "/v1/clinics/", wantStatus: http.StatusUnauthorized, wantCode: "AUTH_UNAUTHENTICATED"},
+		{name: "rejects unknown keys", url: "/v1/clinics/", header: apikey.Header, value: "guess", wantStatus: http.StatusUnauthorized, wantCode: "AUTH_UNAUTHENTICATED"},
+		{name: "serves keys with the scope", url: "/v1/clinics/", header: apikey.Header, value: "reader-secret", wantStatus: http.StatusOK, wantKey: "reader"},
+		{name: "serves bearer tokens", url: "/v1/clinics/", header: "Authorization", value: "Bearer reader-secret", wantStatus: http.StatusOK, wantKey: "reader"},
+		{name: "serves keys with a scope implying the scope", url: "/v1/clinics/", header: apikey.Header, value: "admin-secret", wantStatus: http.StatusOK, wantKey: "admin"},
+		{name: "forbids keys without the scope", url: "/v1/clinics/", header: apikey.Header, value: "ops-secret", wantStatus: http.StatusForbidden, wantCode: "AUTH_FORBIDDEN", wantKey: "ops"},
+		{name: "forbids the metrics without the debug scope", url: "/metrics", header: apikey.Header, value: "reader-secret", wantStatus: http.StatusForbidden, wantCode: "AUTH_FORBIDDEN", wantKey: "reader"},
+		{name: "serves the metrics with the debug scope", url: "/metrics", header: apikey.Header, value: "ops-secret", wantStatus: http.StatusOK, wantKey: "ops"},
+		{name: "rejects pprof without a key", url: "/debug/pprof/", wantStatus: http.StatusUnauthorized, wantCode: "AUTH_UNAUTHENTICATED"},
+		{name: "serves pprof with the debug scope", url: "/debug/pprof/", header: apikey.Header, value: "ops-secret", wantStatus: http.StatusOK, wantKey: "ops"},
+		{name: "rejects unknown debug paths without a key", url: "/debug/a0b1c2", wantStatus: http.StatusUnauthorized, wantCode: "AUTH_UNAUTHENTICATED", wantRoute: "/debug/"},
+		{name: "serves the admin routes with the admin scope", method: http.MethodPost, url: "/v1/clinics/synonyms/reload", header: apikey.Header, value: "admin-secret", wantStatus: http.StatusOK, wantKey: "admin"},
+		{name: "forbids the admin routes without the admin scope", method: http.MethodPost, url: "/v1/clinics/synonyms/reload", header: apikey.Header, value: "reader-secret", wantStatus: http.StatusForbidden, wantCode: "AUTH_FORBIDDEN", wantKey: "reader"},
+		{name: "serves the health checks without a key", url: "/alive", wantStatus: http.StatusOK},
+		{name: "serves the error catalog without a key", url: httputil.ErrorCatalogPath, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.TakeAll()
+
+			if tt.method == "" {
+				tt.method = http.MethodGet
+			}
+
+			request := httptest.NewRequest(tt.method, "http://www.test.com"+tt.url, nil)
+			if tt.header != "" {
+				request.Header.Set(tt.header, tt.value)
+			}
+
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatus, response.Code)
+
+			if tt.wantCode != "" {
+				var res httputil.Response
+				assert.NoError(t, json.NewDecoder(response.Body).Decode(&res))
+				assert.Equal(t, tt.wantCode, res.Code)
+			}
+
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="api"`, response.Header().Get("WWW-Authenticate"))
+			}
+
+			entries := logs.FilterMessage("request served").All()
+			if assert.Len(t, entries, 1) {
+				key, ok := entries[0].ContextMap()["api_key"]
+				if tt.wantKey == "" {
+					assert.False(t, ok)
+				} else {
+					assert.Equal(t, tt.wantKey, key)
+				}
+
+				if tt.wantRoute != "" {
+					assert.Equal(t, tt.wantRoute, entries[0].ContextMap()["route"])
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticationWithoutKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		disabled   bool
+		url        string
+		wantStatus int
+	}{
+		{name: "denies the clinic routes", url: "/v1/clinics/", wantStatus: http.StatusUnauthorized},
+		{name: "denies the metrics", url: "/metrics", wantStatus: http.StatusUnauthorized},
+		{name: "denies the debug routes", url: "/debug/pprof/", wantStatus: http.StatusUnauthorized},
+		{name: "serves the health checks", url: "/alive", wantStatus: http.StatusOK},
+		{name: "serves the clinic routes when disabled", disabled: true, url: "/v1/clinics/", wantStatus: http.StatusOK},
+		{name: "denies the metrics when disabled", disabled: true, url: "/metrics", wantStatus: http.StatusUnauthorized},
+		{name: "denies the debug routes when disabled", disabled: true, url: "/debug/pprof/", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := httputil.NewAuthenticator(nil, tt.disabled)
+			assert.Equal(t, !tt.disabled, auth.Enabled())
+
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil).Maybe()
+
+			routes := chi.NewRouter()
+			routes.With(auth.Require(apikey.ScopeClinicsRead)).Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+
+			mux := httputil.NewBaseMux(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`), auth)
+			mux.Handle("/", routes)
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com"+tt.url, nil)
+			request.Header.Set(apikey.Header, "guess")
+
+			response := httptest.NewRecorder()
+			auth.Authenticate(mux).ServeHTTP(response, request)
+			assert.Equal(t, tt.wantStatus, response.Code)
+		})
+	}
+}
+
+// withDebugKey returns the request as authenticated with a key granted the debug scope, see Authenticator.Authenticate
+func withDebugKey(r *http.Request) *http.Request {
+	return r.WithContext(apikey.With(r.Context(), apikey.Key{ID: "ops", Scopes: []string{apikey.ScopeDebug}}))
+}
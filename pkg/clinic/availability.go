@@ -0,0 +1,136 @@
+package clinic
+
+import (
+	"fmt"
+)
+
+// The match modes of a search time window, they define how the opening hours of a clinic are compared to the window:
+//
+//	covers:   the clinic is open for the whole window, from <= window.from and window.to <= to
+//	overlaps: the clinic is open for at least a minute of the window, from < window.to and window.from < to
+//	within:   the opening hours of the clinic fall inside the window, window.from <= from and to <= window.to
+//
+// A window missing its `from` starts at 00:00 and one missing its `to` ends at 24:00.
+// Opening hours where `to` is not after `from`, e.g. 22:00 to 06:00, run past midnight into the next day.
+const (
+	MatchCovers   = "covers"
+	MatchOverlaps = "overlaps"
+	MatchWithin   = "within"
+)
+
+const minutesPerDay = 24 * 60
+
+// timeWindow is a range of minutes since midnight, the end can exceed a day for ranges running past midnight
+type timeWindow struct {
+	from, to int
+}
+
+// parseClock returns the minutes since midnight of a `HH:MM` time, `24:00` is accepted as the end of the day
+func parseClock(s string) (int, error) {
+	if len(s) != 5 || s[2] != ':' || !isDigits(s[:2]) || !isDigits(s[3:]) {
+		return 0, fmt.Errorf("time %q is not in the HH:MM format", s)
+	}
+
+	hours := int(s[0]-'0')*10 + int(s[1]-'0')
+	minutes := int(s[3]-'0')*10 + int(s[4]-'0')
+
+	if minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("time %q is not a valid time of the day", s)
+	}
+
+	return hours*60 + minutes, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// searchWindow returns the time window of the search, missing bounds default to the start and end of the day
+func searchWindow(from, to string) (timeWindow, error) {
+	window := timeWindow{0, minutesPerDay}
+
+	var err error
+	if from != "" {
+		if window.from, err = parseClock(from); err != nil {
+			return window, err
+		}
+	}
+
+	if to != "" {
+		if window.to, err = parseClock(to); err != nil {
+			return window, err
+		}
+	}
+
+	return window, nil
+}
+
+// openingHours returns the opening hours of a clinic as a time window,
+// hours closing at or before they open are taken to close on the next day
+func openingHours(a Availability) (timeWindow, error) {
+	from, err := parseClock(a.From)
+	if err != nil {
+		return timeWindow{}, err
+	}
+
+	to, err := parseClock(a.To)
+	if err != nil {
+		return timeWindow{}, err
+	}
+
+	if to <= from {
+		to += minutesPerDay
+	}
+
+	return timeWindow{from, to}, nil
+}
+
+// matchAvailability reports whether the opening hours match the window with the given mode,
+// clinics with unreadable opening hours never match
+func matchAvailability(mode string, window timeWindow, a Availability) bool {
+	hours, err := openingHours(a)
+	if err != nil {
+		return false
+	}
+
+	// opening hours running past midnight are also compared against the window on the next day
+	nextDay := timeWindow{window.from + minutesPerDay, window.to + minutesPerDay}
+
+	switch mode {
+	case MatchOverlaps:
+		return hours.overlaps(window) || hours.overlaps(nextDay)
+	case MatchWithin:
+		return window.contains(hours)
+	default:
+		return hours.contains(window) || hours.contains(nextDay)
+	}
+}
+
+func (w timeWindow) contains(other timeWindow) bool {
+	return w.from <= other.from && other.to <= w.to
+}
+
+func (w timeWindow) overlaps(other timeWindow) bool {
+	return w.from < other.to && other.from < w.to
+}
+
+// String formats the window as `HH:MM-HH:MM`, times on the next day are suffixed with `+1`
+func (w timeWindow) String() string {
+	return formatClock(w.from) + "-" + formatClock(w.to)
+}
+
+func formatClock(minutes int) string {
+	suffix := ""
+	if minutes > minutesPerDay {
+		minutes -= minutesPerDay
+		suffix = "+1"
+	}
+
+	return fmt.Sprintf("%02d:%02d%s", minutes/60, minutes%60, suffix)
+}
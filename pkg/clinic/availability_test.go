@@ -0,0 +1,119 @@
+package clinic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchAvailability(t *testing.T) {
+	dayShift := Availability{From: "09:00", To: "17:00"}
+	nightShift := Availability{From: "22:00", To: "06:00"}
+
+	tests := []struct {
+		name         string
+		from, to     string
+		availability Availability
+		covers       bool
+		overlaps     bool
+		within       bool
+	}{
+		{
+			name: "window equal to the opening hours", from: "09:00", to: "17:00", availability: dayShift,
+			covers: true, overlaps: true, within: true,
+		},
+		{
+			name: "window inside the opening hours", from: "11:00", to: "16:00", availability: dayShift,
+			covers: true, overlaps: true, within: false,
+		},
+		{
+			name: "window around the opening hours", from: "08:00", to: "18:00", availability: dayShift,
+			covers: false, overlaps: true, within: true,
+		},
+		{
+			name: "window starting before opening", from: "07:00", to: "10:00", availability: dayShift,
+			covers: false, overlaps: true, within: false,
+		},
+		{
+			name: "window ending at opening", from: "07:00", to: "09:00", availability: dayShift,
+			covers: false, overlaps: false, within: false,
+		},
+		{
+			name: "window starting at closing", from: "17:00", to: "19:00", availability: dayShift,
+			covers: false, overlaps: false, within: false,
+		},
+		{
+			name: "window without end runs until the end of the day", from: "16:00", availability: dayShift,
+			covers: false, overlaps: true, within: false,
+		},
+		{
+			name: "window without start runs from the start of the day", to: "18:00", availability: dayShift,
+			covers: false, overlaps: true, within: true,
+		},
+		{
+			name: "evening window of overnight hours", from: "22:30", to: "23:30", availability: nightShift,
+			covers: true, overlaps: true, within: false,
+		},
+		{
+			name: "early morning window of overnight hours", from: "02:00", to: "05:00", availability: nightShift,
+			covers: true, overlaps: true, within: false,
+		},
+		{
+			name: "window across closing of overnight hours", from: "05:00", to: "08:00", availability: nightShift,
+			covers: false, overlaps: true, within: false,
+		},
+		{
+			name: "daytime window of overnight hours", from: "09:00", to: "17:00", availability: nightShift,
+			covers: false, overlaps: false, within: false,
+		},
+		{
+			name: "whole day window", from: "00:00", to: "24:00", availability: dayShift,
+			covers: false, overlaps: true, within: true,
+		},
+		{
+			name: "unreadable opening hours", from: "09:00", to: "17:00", availability: Availability{From: "9am", To: "5pm"},
+			covers: false, overlaps: false, within: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := searchWindow(tt.from, tt.to)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.covers, matchAvailability(MatchCovers, window, tt.availability), "covers")
+			assert.Equal(t, tt.overlaps, matchAvailability(MatchOverlaps, window, tt.availability), "overlaps")
+			assert.Equal(t, tt.within, matchAvailability(MatchWithin, window, tt.availability), "within")
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr string
+	}{
+		{value: "00:00", want: 0},
+		{value: "09:30", want: 570},
+		{value: "24:00", want: 1440},
+		{value: "9:30", wantErr: `time "9:30" is not in the HH:MM format`},
+		{value: "+1:30", wantErr: `time "+1:30" is not in the HH:MM format`},
+		{value: "banana", wantErr: `time "banana" is not in the HH:MM format`},
+		{value: "24:30", wantErr: `time "24:30" is not a valid time of the day`},
+		{value: "12:60", wantErr: `time "12:60" is not a valid time of the day`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseClock(tt.value)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
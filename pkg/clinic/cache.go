@@ -0,0 +1,235 @@
+package clinic
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+)
+
+var (
+	cacheResultTag = tag.MustNewKey("cache_result")
+	cacheLookups   = stats.Int64(
+		"clinic/search_cache/lookups",
+		"Amount of search result cache lookups",
+		stats.UnitDimensionless,
+	)
+	cacheLookupsView = view.View{
+		Name:        "clinic/search_cache/lookups",
+		Description: "Amount of search result cache lookups by result, hit or miss",
+		TagKeys: []tag.Key{
+			cacheResultTag,
+		},
+		Measure:     cacheLookups,
+		Aggregation: view.Count(),
+	}
+)
+
+// ResultCache is a least recently used cache of the clinics matching a search.
+//
+// Results are cached for the most recent snapshot version only, the cache is emptied when a newer version is seen
+// and lookups against older versions, e.g. by cursors pinned to them, always miss.
+type ResultCache struct {
+	maxEntries int
+	maxResults int
+
+	mu      sync.Mutex
+	version uint64
+	entries *list.List // ordered from most to least recently used
+	index   map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	clinics []Clinic
+}
+
+// NewResultCache creates a ResultCache holding up to maxEntries results of at most maxResults clinics each,
+// the cache is disabled when maxEntries is not positive
+func NewResultCache(maxEntries, maxResults int) *ResultCache {
+	// registering a view multiple times is fine, it will ignore subsequent calls
+	view.Register(&cacheLookupsView)
+
+	return &ResultCache{
+		maxEntries: maxEntries,
+		maxResults: maxResults,
+		entries:    list.New(),
+		index:      make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached clinics of the search with the given key on the snapshot version
+func (c *ResultCache) get(version uint64, key string) ([]Clinic, bool) {
+	clinics, ok := c.lookup(version, key)
+
+	result := "miss"
+	if ok {
+		result = "hit"
+	}
+
+	stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Upsert(cacheResultTag, result),
+		},
+		cacheLookups.M(1),
+	)
+
+	return clinics, ok
+}
+
+func (c *ResultCache) lookup(version uint64, key string) ([]Clinic, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidate(version)
+
+	element, ok := c.index[key]
+	if !ok || version != c.version {
+		return nil, false
+	}
+
+	c.entries.MoveToFront(element)
+
+	return copyClinics(element.Value.(*cacheEntry).clinics), true
+}
+
+// add caches a copy of the clinics of the search with the given key on the snapshot version,
+// evicting the least recently used results beyond the size of the cache
+func (c *ResultCache) add(version uint64, key string, clinics []Clinic) {
+	if c.maxEntries <= 0 || len(clinics) > c.maxResults {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidate(version)
+
+	if version != c.version {
+		return
+	}
+
+	if element, ok := c.index[key]; ok {
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(&cacheEntry{key: key, clinics: copyClinics(clinics)})
+
+	for c.entries.Len() > c.maxEntries {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// invalidate empties the cache when the version is newer than the one of the cached results
+func (c *ResultCache) invalidate(version uint64) {
+	if version <= c.version {
+		return
+	}
+
+	c.version = version
+	c.entries.Init()
+	c.index = make(map[string]*list.Element)
+}
+
+func copyClinics(clinics []Clinic) []Clinic {
+	copied := make([]Clinic, len(clinics))
+	copy(copied, clinics)
+
+	return copied
+}
+
+// search returns the clinics of the snapshot matching the search, from the cache when it holds them,
+// within a `clinic/search` span
+func (f searchFilter) search(ctx context.Context, cache *ResultCache, snapshot *Snapshot) []Clinic {
+	_, span := trace.StartSpan(ctx, "clinic/search")
+	defer span.End()
+
+	key := f.cacheKey()
+
+	clinics, hit := cache.get(snapshot.Version, key)
+	if !hit {
+		clinics = f.apply(snapshot.Clinics)
+		cache.add(snapshot.Version, key, clinics)
+	}
+
+	span.AddAttributes(
+		trace.Int64Attribute("snapshot_version", int64(snapshot.Version)),
+		trace.BoolAttribute("cache_hit", hit),
+		trace.Int64Attribute("results", int64(len(clinics))),
+	)
+
+	return clinics
+}
+
+// cacheKey identifies the search by its normalized params, searches returning the same clinics share a key
+// whatever the case and order of their values. The key holds the synonyms the names searched were expanded to,
+// so that results cached before the dictionary is reloaded are not reused.
+func (f searchFilter) cacheKey() string {
+	key := struct {
+		Name         string   `json:"name,omitempty"`
+		State        []string `json:"state,omitempty"`
+		Type         []string `json:"type,omitempty"`
+		Availability string   `json:"availability,omitempty"`
+		Query        string   `json:"q,omitempty"`
+		Lat          *float64 `json:"lat,omitempty"`
+		Lon          *float64 `json:"lon,omitempty"`
+		RadiusKm     float64  `json:"radius_km,omitempty"`
+	}{
+		State:    normalizedSet(f.params.State, strings.ToLower),
+		Type:     normalizedSet(f.params.Type, nil),
+		Lat:      f.params.Lat,
+		Lon:      f.params.Lon,
+		RadiusKm: f.params.RadiusKm,
+	}
+
+	for _, p := range f.predicates {
+		switch p.field {
+		case "name":
+			// the expected names include the synonyms of the searched name
+			key.Name = p.expected
+		case "availability":
+			key.Availability = p.name + " " + p.expected
+		}
+	}
+
+	if f.query != nil {
+		key.Query = f.query.String()
+	}
+
+	// the key holds strings and numbers only, marshalling it cannot fail
+	data, _ := json.Marshal(key)
+
+	return string(data)
+}
+
+// normalizedSet returns the sorted distinct values, each transformed by normalize when given
+func normalizedSet(values []string, normalize func(string) string) []string {
+	seen := make(map[string]bool, len(values))
+
+	var set []string
+	for _, v := range values {
+		if normalize != nil {
+			v = normalize(v)
+		}
+
+		if !seen[v] {
+			seen[v] = true
+			set = append(set, v)
+		}
+	}
+
+	sort.Strings(set)
+
+	return set
+}
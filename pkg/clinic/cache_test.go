@@ -0,0 +1,124 @@
+package clinic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+)
+
+func TestResultCache(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental},
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet},
+	}
+
+	t.Run("evicts the least recently used results", func(t *testing.T) {
+		cache := NewResultCache(2, 10)
+
+		cache.add(1, "a", clinics)
+		cache.add(1, "b", clinics[:1])
+		_, _ = cache.get(1, "a")
+		cache.add(1, "c", clinics[1:])
+
+		_, ok := cache.get(1, "b")
+		assert.False(t, ok)
+
+		got, ok := cache.get(1, "a")
+		assert.True(t, ok)
+		assert.Equal(t, clinics, got)
+
+		got, ok = cache.get(1, "c")
+		assert.True(t, ok)
+		assert.Equal(t, clinics[1:], got)
+	})
+
+	t.Run("is emptied by a newer snapshot version", func(t *testing.T) {
+		cache := NewResultCache(2, 10)
+
+		cache.add(1, "a", clinics)
+
+		_, ok := cache.get(2, "a")
+		assert.False(t, ok)
+
+		// results of older versions are neither served nor cached
+		cache.add(1, "a", clinics)
+		_, ok = cache.get(1, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("skips results above the size limit", func(t *testing.T) {
+		cache := NewResultCache(2, 1)
+
+		cache.add(1, "a", clinics)
+
+		_, ok := cache.get(1, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("returns copies of the cached results", func(t *testing.T) {
+		cache := NewResultCache(2, 10)
+
+		cache.add(1, "a", clinics)
+
+		got, _ := cache.get(1, "a")
+		got[0].Name = "Changed"
+
+		got, _ = cache.get(1, "a")
+		assert.Equal(t, "Good Health Home", got[0].Name)
+	})
+}
+
+func TestSearchCacheKey(t *testing.T) {
+	key := func(params SearchParams) string {
+		filter, err := newSearchFilter(params, nil)
+		assert.NoError(t, err)
+
+		return filter.cacheKey()
+	}
+
+	assert.Equal(t,
+		key(SearchParams{Name: "Good", State: StringList{"FL", "ca"}, Type: StringList{"vet", "dental"}, Q: "type:vet  state:CA"}),
+		key(SearchParams{Name: "good", State: StringList{"CA", "fl", "CA"}, Type: StringList{"dental", "vet"}, Q: "type:vet AND state:CA"}),
+	)
+
+	assert.Equal(t,
+		key(SearchParams{From: "09:00", To: "17:00"}),
+		key(SearchParams{From: "09:00", To: "17:00", Match: MatchCovers}),
+	)
+
+	assert.NotEqual(t,
+		key(SearchParams{From: "09:00", To: "17:00"}),
+		key(SearchParams{From: "09:00", To: "17:00", Match: MatchOverlaps}),
+	)
+}
+
+func TestSearchCacheStats(t *testing.T) {
+	lookups := func(result string) int64 {
+		rows, err := view.RetrieveData(cacheLookupsView.Name)
+		assert.NoError(t, err)
+
+		for _, row := range rows {
+			if row.Tags[0].Value == result {
+				return row.Data.(*view.CountData).Value
+			}
+		}
+
+		return 0
+	}
+
+	cache := NewResultCache(2, 10)
+	snapshot := &Snapshot{Version: 1, Clinics: []Clinic{{Name: "Good Health Home", State: "FL", Type: TypeDental}}}
+
+	filter, err := newSearchFilter(SearchParams{State: StringList{"FL"}}, nil)
+	assert.NoError(t, err)
+
+	hits, misses := lookups("hit"), lookups("miss")
+
+	assert.Equal(t, snapshot.Clinics, filter.search(context.Background(), cache, snapshot))
+	assert.Equal(t, snapshot.Clinics, filter.search(context.Background(), cache, snapshot))
+
+	assert.Equal(t, hits+1, lookups("hit"))
+	assert.Equal(t, misses+1, lookups("miss"))
+}
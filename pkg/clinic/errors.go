@@ -0,0 +1,24 @@
+package clinic
+
+import (
+	"net/http"
+
+	"github.com/scratchpay_ademola/internal/httputil"
+)
+
+// The error codes of the clinic endpoints, listed by `GET /v1/errors`.
+// Codes are part of the API: clients branch on them, they are never renamed nor reused.
+var (
+	codeUnreadableBody      = httputil.NewErrorCode("REQUEST_UNREADABLE_BODY", http.StatusBadRequest, true, "unreadable request body")
+	codeInvalidBody         = httputil.NewErrorCode("SEARCH_INVALID_BODY", http.StatusBadRequest, false, "invalid json params")
+	codeInvalidAttributes   = httputil.NewErrorCode("SEARCH_INVALID_ATTRIBUTES", http.StatusBadRequest, false, "invalid attributes")
+	codeInvalidQuery        = httputil.NewErrorCode("SEARCH_INVALID_QUERY", http.StatusBadRequest, false, "invalid query")
+	codeInvalidBatch        = httputil.NewErrorCode("BATCH_INVALID", http.StatusBadRequest, false, "invalid batch")
+	codeInvalidListParams   = httputil.NewErrorCode("LIST_INVALID_PARAMS", http.StatusBadRequest, false, "invalid params")
+	codeInvalidCursor       = httputil.NewErrorCode("LIST_INVALID_CURSOR", http.StatusBadRequest, false, "invalid cursor")
+	codeCursorExpired       = httputil.NewErrorCode("LIST_CURSOR_EXPIRED", http.StatusGone, false, "cursor expired")
+	codeNotAcceptable       = httputil.NewErrorCode("LIST_NOT_ACCEPTABLE", http.StatusNotAcceptable, false, "not acceptable")
+	codeUpstreamUnavailable = httputil.NewErrorCode("CLINIC_UPSTREAM_UNAVAILABLE", http.StatusServiceUnavailable, true, "error fetching all clinics")
+	codeListingFailed       = httputil.NewErrorCode("CLINIC_LISTING_FAILED", http.StatusInternalServerError, false, "error listing clinics")
+	codeSynonymsReload      = httputil.NewErrorCode("SYNONYMS_RELOAD_FAILED", http.StatusInternalServerError, false, "error reloading synonyms")
+)
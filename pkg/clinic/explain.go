@@ -0,0 +1,103 @@
+package clinic
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Explanation details how a search evaluated a clinic
+type Explanation struct {
+	Clinic     string            `json:"clinic"`
+	Matched    bool              `json:"matched"`
+	Predicates []PredicateResult `json:"predicates"`
+}
+
+// PredicateResult is the outcome of a predicate of a search for a clinic,
+// Value and Expected hold the normalized values the predicate compared
+type PredicateResult struct {
+	Predicate string `json:"predicate"`
+	Field     string `json:"field"`
+	Value     string `json:"value"`
+	Expected  string `json:"expected"`
+	Passed    bool   `json:"passed"`
+}
+
+// explainRequest is the explain mode requested through the `explain` and `explain_name` query parameters,
+// either explaining the returned clinics or every clinic of the snapshot with the given name
+type explainRequest struct {
+	enabled bool
+	name    string
+}
+
+func getExplainRequest(r *http.Request) (explainRequest, error) {
+	req := explainRequest{name: r.URL.Query().Get("explain_name")}
+
+	enabled, err := boolParam(r, "explain")
+	if err != nil {
+		return req, err
+	}
+
+	req.enabled = enabled || req.name != ""
+
+	return req, nil
+}
+
+// explanations returns the explanation of each returned clinic, or of the clinics of the snapshot named in the request
+func (e explainRequest) explanations(f searchFilter, snapshot *Snapshot, page []Clinic) []Explanation {
+	clinics := page
+	if e.name != "" {
+		clinics = nil
+		for _, c := range snapshot.Clinics {
+			if strings.EqualFold(c.Name, e.name) {
+				clinics = append(clinics, c)
+			}
+		}
+	}
+
+	explanations := make([]Explanation, 0, len(clinics))
+	for _, c := range clinics {
+		explanations = append(explanations, f.explain(c))
+	}
+
+	return explanations
+}
+
+// explain evaluates every predicate of the search against the clinic,
+// the terms of a query are reported after the query predicate they belong to
+func (f searchFilter) explain(c Clinic) Explanation {
+	explanation := Explanation{
+		Clinic:     c.Name,
+		Matched:    true,
+		Predicates: make([]PredicateResult, 0, len(f.predicates)),
+	}
+
+	for _, p := range f.predicates {
+		passed := p.test(c)
+
+		explanation.Matched = explanation.Matched && passed
+		explanation.Predicates = append(explanation.Predicates, PredicateResult{
+			Predicate: p.name,
+			Field:     p.field,
+			Value:     p.value(c),
+			Expected:  p.expected,
+			Passed:    passed,
+		})
+
+		if p.field == "q" && f.query != nil {
+			for _, term := range f.query.terms() {
+				field := queryFields[term.field]
+				expected := append([]string{term.value}, term.variants...)
+
+				explanation.Predicates = append(explanation.Predicates, PredicateResult{
+					Predicate: "q term",
+					Field:     term.field,
+					Value:     strings.ToLower(field.value(c)),
+					Expected:  strings.ToLower(strings.Join(expected, ", ")),
+					Passed:    term.eval(c),
+				})
+			}
+		}
+	}
+
+	return explanation
+}
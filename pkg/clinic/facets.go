@@ -0,0 +1,56 @@
+package clinic
+
+import (
+	"fmt"
+)
+
+// facetUnknown is the bucket of clinics missing the value of a facet
+const facetUnknown = "unknown"
+
+// FacetsResponse holds the number of clinics matching a search, and unless only the count was requested,
+// how many of them fall into each value of the facets
+type FacetsResponse struct {
+	Total  int                       `json:"total"`
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+}
+
+// facets maps the name of each facet to the bucket a clinic falls into, clinics naming their state by code or name
+// falling into the bucket of its code
+var facets = map[string]func(c Clinic) string{
+	"state": func(c Clinic) string { return stateCode(c.State) },
+	"type":  func(c Clinic) string { return c.Type },
+	"opens": openingHourBucket,
+}
+
+// openingHourBucket returns the one hour range the clinic opens in, e.g. `08:00-09:00`,
+// a clinic opening at `24:00` opening at midnight
+func openingHourBucket(c Clinic) string {
+	from, err := parseClock(c.Availability.From)
+	if err != nil {
+		return facetUnknown
+	}
+
+	hour := from / 60 % 24
+
+	return fmt.Sprintf("%02d:00-%02d:00", hour, hour+1)
+}
+
+// countFacets counts the clinics falling into each bucket of every facet
+func countFacets(clinics []Clinic) map[string]map[string]int {
+	counts := make(map[string]map[string]int, len(facets))
+
+	for name, bucket := range facets {
+		counts[name] = make(map[string]int)
+
+		for _, c := range clinics {
+			value := bucket(c)
+			if value == "" {
+				value = facetUnknown
+			}
+
+			counts[name][value]++
+		}
+	}
+
+	return counts
+}
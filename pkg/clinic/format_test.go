@@ -0,0 +1,157 @@
+package clinic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+func TestListFormats(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", City: "Oakland", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		url             string
+		accept          string
+		body            string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+		wantNextCursor  string
+	}{
+		{
+			name:            "defaults to json",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?fields=name",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        "{\"data\":[{\"name\":\"Good Health Home\"},{\"name\":\"National Veterinary Clinic\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name:            "csv from the accept header",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/",
+			accept:          "text/csv",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody: "name,state,city,type,availability.from,availability.to,distance_km\n" +
+				"Good Health Home,FL,,dental,15:00,20:00,\n" +
+				"National Veterinary Clinic,CA,Oakland,vet,15:00,22:30,\n",
+		},
+		{
+			name:            "csv of the requested fields",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?fields=name,availability&size=1",
+			accept:          "text/csv;q=0.9, application/json;q=0.5",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "name,availability.from,availability.to\nGood Health Home,15:00,20:00\n",
+			wantNextCursor:  "eyJ2IjoxLCJkIjoiMjJmZGUzODcxNmU2ZDBhYiIsIm8iOjF9.WyGDiMMc_5eDgms4iOZGj6Pw8d4oCkMeb-Zmwpbcwak",
+		},
+		{
+			name:            "ndjson from the format param",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search?format=ndjson&fields=name,state",
+			accept:          "application/json",
+			body:            `{"type": "vet"}`,
+			wantCode:        http.StatusOK,
+			wantContentType: "application/x-ndjson; charset=utf-8",
+			wantBody:        "{\"name\":\"National Veterinary Clinic\",\"state\":\"CA\"}\n",
+		},
+		{
+			name:            "ndjson of whole clinics",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search",
+			accept:          "application/x-ndjson",
+			body:            `{"state": "FL"}`,
+			wantCode:        http.StatusOK,
+			wantContentType: "application/x-ndjson; charset=utf-8",
+			wantBody:        "{\"name\":\"Good Health Home\",\"state\":\"FL\",\"type\":\"dental\",\"availability\":{\"from\":\"15:00\",\"to\":\"20:00\"}}\n",
+		},
+		{
+			name:            "wildcards pick json",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?fields=name&size=1",
+			accept:          "text/html, */*;q=0.1",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        "{\"data\":[{\"name\":\"Good Health Home\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":1,\"total_pages\":2,\"next_cursor\":\"eyJ2IjoxLCJkIjoiMjJmZGUzODcxNmU2ZDBhYiIsIm8iOjF9.WyGDiMMc_5eDgms4iOZGj6Pw8d4oCkMeb-Zmwpbcwak\"}}\n",
+		},
+		{
+			name:            "unsupported accept header",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/",
+			accept:          "application/xml, text/csv;q=0",
+			wantCode:        http.StatusNotAcceptable,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        "{\"error\":\"not acceptable\",\"code\":\"LIST_NOT_ACCEPTABLE\",\"messages\":{\"format\":\"the supported formats are application/json, text/csv, application/x-ndjson\"}}\n",
+		},
+		{
+			name:            "unsupported format param",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search?format=xml",
+			body:            `{}`,
+			wantCode:        http.StatusNotAcceptable,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        "{\"error\":\"not acceptable\",\"code\":\"LIST_NOT_ACCEPTABLE\",\"messages\":{\"format\":\"the supported formats are application/json, text/csv, application/x-ndjson\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(append([]Clinic(nil), clinics...), nil).Maybe()
+
+			request := httptest.NewRequest(tt.method, "http://www.test.com"+tt.url, strings.NewReader(tt.body))
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+
+			response := httptest.NewRecorder()
+
+			store := NewSnapshotStore(fetcherMock, 0, 1)
+
+			r := chi.NewRouter()
+			r.Get("/v1/clinics/", GetAllClinics(store, testCursors))
+			r.Post("/v1/clinics/search", Search(store, NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantContentType, response.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantNextCursor, response.Header().Get("X-Next-Cursor"))
+			assert.Equal(t, "Accept", response.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{
+		{Name: "=HYPERLINK(\"http://evil.test\")", State: "FL", Type: TypeDental},
+		{Name: "+1 Dental", State: "@CA", City: "-Oakland", Type: TypeVet},
+		{Name: "Bay Pets", State: "CA", Type: TypeVet},
+	}, nil).Maybe()
+
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/?format=csv&fields=name,state,city", nil)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+	r.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "name,state,city\n"+
+		"\"'=HYPERLINK(\"\"http://evil.test\"\")\",FL,\n"+
+		"'+1 Dental,'@CA,'-Oakland\n"+
+		"Bay Pets,CA,\n", response.Body.String())
+}
@@ -3,18 +3,15 @@ package clinic
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/scratchpay_ademola/internal/httputil"
 	"github.com/scratchpay_ademola/internal/logger"
 	"github.com/scratchpay_ademola/internal/validatorutil"
 
-	"fmt"
-	"time"
-
-	"github.com/thedevsaddam/gojsonq/v2"
 	"go.uber.org/zap"
 )
 
@@ -23,123 +20,315 @@ const (
 	dentalClinicsURL = "https://storage.googleapis.com/scratchpay-code-challenge/dental-clinics.json"
 )
 
+// DataFetcher fetches the clinics of the providers,
+// the context carries the request ID of the request triggering the fetch, if any
 type DataFetcher interface {
-	GetClinicData(logger *zap.Logger) ([]Clinic, error)
+	GetClinicData(ctx context.Context, logger *zap.Logger) ([]Clinic, error)
 }
 
-func GetAllClinics(dataFetcher DataFetcher) http.HandlerFunc {
+func GetAllClinics(store *SnapshotStore, cursors *httputil.CursorCodec) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		l := logger.From(context.Background())
+		l := logger.From(r.Context())
 		attrErrMessages := validatorutil.GetAttributeErrorMessages()
 
-		data, err := dataFetcher.GetClinicData(l)
+		format, err := listFormat(w, r)
+		if err != nil {
+			writePageError(w, r, l, err, attrErrMessages)
+			return
+		}
+
+		list, err := getPageRequest(r, cursors, "")
 		if err != nil {
-			l.Error("error fetching clinic data")
-			httputil.JSONError(w, http.StatusInternalServerError, "error fetching all clinics", attrErrMessages)
+			writePageError(w, r, l, err, attrErrMessages)
 			return
 		}
 
-		httputil.JSONSuccess(w, http.StatusOK, data)
+		list.format = format
+
+		snapshot, err := list.snapshot(r.Context(), store, l)
+		if err != nil {
+			writePageError(w, r, l, err, attrErrMessages)
+			return
+		}
+
+		data := make([]Clinic, len(snapshot.Clinics))
+		copy(data, snapshot.Clinics)
+
+		writeList(w, r, l, cursors, list, snapshot, data)
 	}
 }
 
-func Search(fetcher DataFetcher) http.HandlerFunc {
+func Search(store *SnapshotStore, cache *ResultCache, synonyms *SynonymStore, cursors *httputil.CursorCodec) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		l := logger.From(context.Background())
+		l := logger.From(r.Context())
 		attrErrMessages := validatorutil.GetAttributeErrorMessages()
 
-		body, err := ioutil.ReadAll(r.Body)
+		format, err := listFormat(w, r)
 		if err != nil {
-			l.Error("failed fetching json body")
-			httputil.JSONError(w, http.StatusBadRequest, "internal server error", attrErrMessages)
+			writePageError(w, r, l, err, attrErrMessages)
 			return
 		}
 
 		var params SearchParams
-		err = json.Unmarshal(body, &params)
+
+		if r.Method == http.MethodGet {
+			params, err = searchParamsFromQuery(r.URL.Query())
+			if err != nil {
+				writeSearchError(w, r, err)
+				return
+			}
+		} else {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				l.Error("failed reading request body", zap.Error(err))
+				httputil.JSONError(w, r, codeUnreadableBody, attrErrMessages)
+				return
+			}
+
+			err = json.Unmarshal(body, &params)
+			if err != nil {
+				l.Error("Failed parsing json to params struct", zap.Error(err))
+				httputil.JSONError(w, r, codeInvalidBody, attrErrMessages)
+				return
+			}
+		}
+
+		filter, err := newSearchFilter(params, synonyms.Current())
+		if err != nil {
+			writeSearchError(w, r, err)
+			return
+		}
+
+		list, err := getPageRequest(r, cursors, params.fingerprint())
+		if err != nil {
+			writePageError(w, r, l, err, attrErrMessages)
+			return
+		}
+
+		list.format = format
+
+		explain, err := getExplainRequest(r)
 		if err != nil {
-			l.Error("Failed parsing json to params struct", zap.Error(err))
-			httputil.JSONError(w, http.StatusBadRequest, "invalid json params", attrErrMessages)
+			writePageError(w, r, l, err, attrErrMessages)
 			return
 		}
 
-		validate := validatorutil.GetValidator()
+		highlight, err := boolParam(r, "highlight")
+		if err != nil {
+			writePageError(w, r, l, err, attrErrMessages)
+			return
+		}
 
-		err = validate.Struct(params)
+		snapshot, err := list.snapshot(r.Context(), store, l)
 		if err != nil {
-			attrErrMessages = validatorutil.GetTranslatedErrors(err)
-			httputil.JSONError(w, http.StatusBadRequest, "invalid attributes", attrErrMessages)
+			writePageError(w, r, l, err, attrErrMessages)
 			return
 		}
 
-		data, err := fetcher.GetClinicData(l)
+		clinics := filter.search(r.Context(), cache, snapshot)
+
+		res, page, err := listPage(cursors, list, snapshot, clinics)
 		if err != nil {
-			l.Error("error fetching clinic data")
-			httputil.JSONError(w, http.StatusInternalServerError, "error fetching all clinics", attrErrMessages)
+			l.Error("failed listing clinics", zap.Error(err))
+			httputil.JSONError(w, r, codeListingFailed, attrErrMessages)
 			return
 		}
 
-		d, err := json.Marshal(data)
-		query := gojsonq.New().
-			FromString(string(d))
+		if explain.enabled {
+			res.Explain = explain.explanations(filter, snapshot, page)
+		}
+
+		if highlight {
+			res.Highlight = filter.highlights(page)
+		}
+
+		if res.Meta.Total == 0 {
+			res.Suggestions = filter.suggestions(snapshot)
+		}
+
+		writeListResponse(w, r, l, list, res, page)
+	}
+}
+
+// maxBatchSize is the maximum number of searches in a batch
+const maxBatchSize = 20
 
-		query.Macro("date<=", dateLessOrEqualTo)
-		query.Macro("date>=", dateGreaterOrEqualTo)
+func SearchBatch(store *SnapshotStore, cache *ResultCache, synonyms *SynonymStore, cursors *httputil.CursorCodec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logger.From(r.Context())
+		attrErrMessages := validatorutil.GetAttributeErrorMessages()
 
-		if params.Name != "" {
-			query.WhereContains("name", params.Name)
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			l.Error("failed reading request body", zap.Error(err))
+			httputil.JSONError(w, r, codeUnreadableBody, attrErrMessages)
+			return
+		}
+
+		var searches []BatchSearch
+		err = json.Unmarshal(body, &searches)
+		if err != nil {
+			l.Error("Failed parsing json to batch search", zap.Error(err))
+			httputil.JSONError(w, r, codeInvalidBody, attrErrMessages)
+			return
+		}
+
+		if len(searches) == 0 || len(searches) > maxBatchSize {
+			attrErrMessages["searches"] = fmt.Sprintf("a batch holds between 1 and %d searches", maxBatchSize)
+			httputil.JSONError(w, r, codeInvalidBatch, attrErrMessages)
+			return
 		}
 
-		if params.State != "" {
-			query.WhereContains("state", params.State)
+		names := make(map[string]bool, len(searches))
+		for i, search := range searches {
+			key := fmt.Sprintf("searches[%d]", i)
+
+			if search.Name == "" {
+				attrErrMessages[key] = "the name of the search is required"
+			} else if names[search.Name] {
+				attrErrMessages[key] = fmt.Sprintf("the name %q is already used by another search", search.Name)
+			}
+
+			names[search.Name] = true
 		}
 
-		if params.To != "" {
-			query.Where("availability.from", "date>=", params.From)
+		if len(attrErrMessages) > 0 {
+			httputil.JSONError(w, r, codeInvalidBatch, attrErrMessages)
+			return
 		}
 
-		if params.From != "" {
-			query.Where("availability.to", "date<=", params.To)
+		// a cursor belongs to a single listing, each search of the batch has its own
+		if r.URL.Query().Get("cursor") != "" {
+			attrErrMessages["cursor"] = "cursor is not supported by batch searches, page a search through the search endpoint"
+			httputil.JSONError(w, r, codeInvalidCursor, attrErrMessages)
+			return
 		}
 
-		result := query.Get()
+		list, err := getPageRequest(r, cursors, "")
+		if err != nil {
+			writePageError(w, r, l, err, attrErrMessages)
+			return
+		}
 
-		var clinics []Clinic
-		err = mapstructure.Decode(result, &clinics)
+		// every search of the batch runs against the same snapshot
+		snapshot, err := store.Current(r.Context(), l)
 		if err != nil {
-			l.Error("failed decoding clinics", zap.Error(err))
-			httputil.JSONError(w, http.StatusInternalServerError, "error searching clinics", attrErrMessages)
+			l.Error("error fetching clinic data", zap.Error(err))
+			httputil.JSONError(w, r, codeUpstreamUnavailable, attrErrMessages)
 			return
 		}
 
-		httputil.JSONSuccess(w, http.StatusOK, clinics)
+		res := BatchSearchResponse{
+			Results: make(map[string]BatchSearchResult, len(searches)),
+		}
+
+		for _, search := range searches {
+			res.Results[search.Name] = runBatchSearch(r.Context(), l, cache, synonyms.Current(), cursors, list, snapshot, search)
+		}
+
+		httputil.JSONSuccess(w, http.StatusOK, res)
 	}
 }
 
-const layout = "2006-01-02"
+// runBatchSearch runs one search of a batch, failures are reported in its result and don't affect the other searches
+func runBatchSearch(ctx context.Context, l *zap.Logger, cache *ResultCache, synonyms *Synonyms, cursors *httputil.CursorCodec, list pageRequest, snapshot *Snapshot, search BatchSearch) BatchSearchResult {
+	failed := func(code httputil.ErrorCode, messages map[string]string) BatchSearchResult {
+		res := httputil.NewResponse(code, messages)
+		return BatchSearchResult{Response: &res}
+	}
+
+	var params SearchParams
+	if len(search.Params) > 0 {
+		if err := json.Unmarshal(search.Params, &params); err != nil {
+			return failed(codeInvalidBody, validatorutil.GetAttributeErrorMessages())
+		}
+	}
+
+	filter, err := newSearchFilter(params, synonyms)
+	if err != nil {
+		if searchErr, ok := err.(searchError); ok {
+			return failed(searchErr.code, searchErr.messages)
+		}
 
-func dateLessOrEqualTo(x, y interface{}) (bool, error) {
-	xs, okx := x.(string)
-	ys, oky := y.(string)
-	if !okx || !oky {
-		return false, fmt.Errorf("date support for string only")
+		return failed(codeInvalidAttributes.WithMessage(err.Error()), validatorutil.GetAttributeErrorMessages())
 	}
 
-	t1, _ := time.Parse(layout, xs)
-	t2, _ := time.Parse(layout, ys)
+	clinics := filter.search(ctx, cache, snapshot)
+
+	// the cursor of each search continues on the search endpoint with the same params
+	list.query = params.fingerprint()
+
+	page, _, err := listPage(cursors, list, snapshot, clinics)
+	if err != nil {
+		l.Error("failed listing clinics", zap.String("search", search.Name), zap.Error(err))
+		return failed(codeListingFailed, validatorutil.GetAttributeErrorMessages())
+	}
 
-	return t1.Unix() <= t2.Unix(), nil
+	if page.Meta.Total == 0 {
+		page.Suggestions = filter.suggestions(snapshot)
+	}
+
+	return BatchSearchResult{ListResponse: &page}
 }
 
-func dateGreaterOrEqualTo(x, y interface{}) (bool, error) {
-	xs, okx := x.(string)
-	ys, oky := y.(string)
-	if !okx || !oky {
-		return false, fmt.Errorf("date support for string only")
+func GetFacets(store *SnapshotStore, cache *ResultCache, synonyms *SynonymStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logger.From(r.Context())
+		attrErrMessages := validatorutil.GetAttributeErrorMessages()
+
+		params, err := searchParamsFromQuery(r.URL.Query())
+		if err != nil {
+			writeSearchError(w, r, err)
+			return
+		}
+
+		filter, err := newSearchFilter(params, synonyms.Current())
+		if err != nil {
+			writeSearchError(w, r, err)
+			return
+		}
+
+		countOnly, err := strconv.ParseBool(r.URL.Query().Get("count_only"))
+		if err != nil && r.URL.Query().Get("count_only") != "" {
+			attrErrMessages["count_only"] = "count_only must be a boolean"
+			httputil.JSONError(w, r, codeInvalidAttributes, attrErrMessages)
+			return
+		}
+
+		snapshot, err := store.Current(r.Context(), l)
+		if err != nil {
+			l.Error("error fetching clinic data", zap.Error(err))
+			httputil.JSONError(w, r, codeUpstreamUnavailable, attrErrMessages)
+			return
+		}
+
+		clinics := filter.search(r.Context(), cache, snapshot)
+
+		res := FacetsResponse{Total: len(clinics)}
+		if !countOnly {
+			res.Facets = countFacets(clinics)
+		}
+
+		httputil.JSONSuccess(w, http.StatusOK, res)
 	}
+}
 
-	t1, _ := time.Parse(layout, xs)
-	t2, _ := time.Parse(layout, ys)
+// ReloadSynonyms reloads the synonym dictionary from its file when it changed, rather than waiting for the next check
+func ReloadSynonyms(synonyms *SynonymStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logger.From(r.Context())
 
-	return t1.Unix() >= t2.Unix(), nil
+		reloaded, err := synonyms.Reload()
+		if err != nil {
+			l.Error("failed reloading synonyms, keeping the current ones", zap.Error(err))
+			httputil.JSONError(w, r, codeSynonymsReload, validatorutil.GetAttributeErrorMessages())
+			return
+		}
+
+		if reloaded {
+			l.Info("reloaded synonyms")
+		}
+
+		httputil.JSONSuccess(w, http.StatusOK, SynonymsReloadResponse{Reloaded: reloaded})
+	}
 }
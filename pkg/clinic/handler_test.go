@@ -1,18 +1,26 @@
 package clinic
 
 import (
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
 	"github.com/stretchr/testify/assert"
 	m "github.com/stretchr/testify/mock"
 )
 
+var testCursors = httputil.NewCursorCodec([]byte("test-secret"))
+
+// testSynonyms holds no dictionary, the searched names are not expanded
+var testSynonyms = &SynonymStore{}
+
 func TestGet(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -24,15 +32,15 @@ func TestGet(t *testing.T) {
 		{
 			name: "error while fetching data",
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).Return(nil, errors.New("random network error"))
+				mock.On("GetClinicData", m.Anything, m.Anything).Return(nil, errors.New("random network error"))
 			},
-			wantCode: http.StatusInternalServerError,
-			wantBody: "{\"error\":\"error fetching all clinics\",\"messages\":{}}\n",
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "{\"error\":\"error fetching all clinics\",\"code\":\"CLINIC_UPSTREAM_UNAVAILABLE\",\"messages\":{}}\n",
 		},
 		{
 			name: "check if user exist success",
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).Return([]Clinic{
+				mock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{
 					{
 						Name:  "Scratchpay Official practice",
 						State: "FL",
@@ -44,7 +52,7 @@ func TestGet(t *testing.T) {
 				}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 	}
 
@@ -59,7 +67,7 @@ func TestGet(t *testing.T) {
 			response := httptest.NewRecorder()
 
 			r := chi.NewRouter()
-			r.Get("/v1/clinics/", GetAllClinics(fetcherMock))
+			r.Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
 			r.ServeHTTP(response, request)
 
 			body, _ := ioutil.ReadAll(response.Body)
@@ -83,29 +91,79 @@ func TestSearch(t *testing.T) {
 			name:     "invalid request body",
 			body:     `{`,
 			wantCode: http.StatusBadRequest,
-			wantBody: "{\"error\":\"invalid json params\",\"messages\":{}}\n",
+			wantBody: "{\"error\":\"invalid json params\",\"code\":\"SEARCH_INVALID_BODY\",\"messages\":{}}\n",
 		},
 		{
 			name:     "invalid params payload",
 			body:     `{"name":"Good health", "invalid_key": "sample"`,
 			wantCode: http.StatusBadRequest,
-			wantBody: "{\"error\":\"invalid json params\",\"messages\":{}}\n",
+			wantBody: "{\"error\":\"invalid json params\",\"code\":\"SEARCH_INVALID_BODY\",\"messages\":{}}\n",
+		},
+		{
+			name:     "invalid query syntax",
+			body:     `{"q": "(state:CA OR"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid query\",\"code\":\"SEARCH_INVALID_QUERY\",\"messages\":{\"q\":\"syntax error at position 13: expected a term but found end of query\"}}\n",
+		},
+		{
+			name: "search matches by query",
+			body: `{"q": "(state:CA OR state:FL) AND type:vet AND NOT name:\"emergency\""}`,
+			setupFetcherMock: func(mock *DataFetcherMock) {
+				mock.On("GetClinicData", m.Anything, m.Anything).
+					Return([]Clinic{
+						{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "09:00", To: "20:00"}},
+						{Name: "Emergency Pets", State: "FL", Type: TypeVet, Availability: Availability{From: "00:00", To: "23:59"}},
+						{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+					}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"National Veterinary Clinic\",\"state\":\"CA\",\"type\":\"vet\",\"availability\":{\"from\":\"15:00\",\"to\":\"22:30\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name:     "invalid availability time",
+			body:     `{"from": "banana"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"from\":\"from must be a time in the HH:MM format\"}}\n",
+		},
+		{
+			name:     "availability window ending before it starts",
+			body:     `{"from": "18:00", "to": "09:00"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"from\":\"from must be before to\"}}\n",
+		},
+		{
+			name:     "unknown states and values too long",
+			body:     `{"name": "` + strings.Repeat("a", 101) + `", "state": ["CA", "Atlantis"], "to": "25:00"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"name\":\"name must be a maximum of 100 characters in length\",\"state[1]\":\"state[1] must be a known state code or name\",\"to\":\"to must be a time in the HH:MM format\"}}\n",
+		},
+		{
+			name:     "state too long",
+			body:     `{"state": ["CA", "` + strings.Repeat("a", 51) + `"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"state[1]\":\"state[1] must be a maximum of 50 characters in length\"}}\n",
+		},
+		{
+			name:     "invalid availability match mode",
+			body:     `{"from": "09:00", "match": "around"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"match\":\"match must be one of covers, overlaps or within\"}}\n",
 		},
 		{
 			name: "error while searching",
 			body: `{"name": "Good ","state": "FL"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).Return(nil,
+				mock.On("GetClinicData", m.Anything, m.Anything).Return(nil,
 					errors.New("random error"))
 			},
-			wantCode: http.StatusInternalServerError,
-			wantBody: "{\"error\":\"error fetching all clinics\",\"messages\":{}}\n",
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "{\"error\":\"error fetching all clinics\",\"code\":\"CLINIC_UPSTREAM_UNAVAILABLE\",\"messages\":{}}\n",
 		},
 		{
 			name: "search returns no match",
 			body: `{"name": "Good ","state": "FL"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -118,13 +176,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[]\n",
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
 		},
 		{
 			name: "search matches by name",
 			body: `{"name": "Scratchpay Official practice"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -145,13 +203,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 		{
 			name: "search matches by state",
 			body: `{"state": "California"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -172,13 +230,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 		{
 			name: "search fails when name and state don't match ",
 			body: `{"state": "FL", "name": "Good Health"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -199,13 +257,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[]\n",
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
 		},
 		{
 			name: "search matches by name & state",
 			body: `{"state": "California", "name": "Good Health"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -226,13 +284,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 		{
 			name: "search matches by availability (from & to)",
 			body: `{"from": "09:00", "to": "20:00"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -253,13 +311,13 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}},{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}},{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 		{
 			name: "search matches by availability within range",
 			body: `{"from": "11:00", "to": "16:00"}`,
 			setupFetcherMock: func(mock *DataFetcherMock) {
-				mock.On("GetClinicData", m.Anything).
+				mock.On("GetClinicData", m.Anything, m.Anything).
 					Return([]Clinic{
 						{
 							Name:  "Scratchpay Official practice",
@@ -280,7 +338,63 @@ func TestSearch(t *testing.T) {
 					}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: "[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}},{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}]\n",
+			wantBody: "{\"data\":[{\"name\":\"Scratchpay Official practice\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}},{\"name\":\"Good Health\",\"state\":\"California\",\"availability\":{\"from\":\"09:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name: "availability covers by default",
+			body: `{"from": "10:00", "to": "11:00"}`,
+			setupFetcherMock: func(mock *DataFetcherMock) {
+				mock.On("GetClinicData", m.Anything, m.Anything).
+					Return([]Clinic{
+						{Name: "Morning Clinic", State: "FL", Availability: Availability{From: "08:00", To: "12:00"}},
+						{Name: "Day Clinic", State: "FL", Availability: Availability{From: "09:00", To: "17:00"}},
+						{Name: "Night Clinic", State: "FL", Availability: Availability{From: "20:00", To: "02:00"}},
+					}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Morning Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"08:00\",\"to\":\"12:00\"}},{\"name\":\"Day Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"17:00\"}}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name: "availability overlaps",
+			body: `{"from": "11:00", "to": "21:00", "match": "overlaps"}`,
+			setupFetcherMock: func(mock *DataFetcherMock) {
+				mock.On("GetClinicData", m.Anything, m.Anything).
+					Return([]Clinic{
+						{Name: "Morning Clinic", State: "FL", Availability: Availability{From: "08:00", To: "12:00"}},
+						{Name: "Day Clinic", State: "FL", Availability: Availability{From: "09:00", To: "17:00"}},
+						{Name: "Night Clinic", State: "FL", Availability: Availability{From: "20:00", To: "02:00"}},
+					}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Morning Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"08:00\",\"to\":\"12:00\"}},{\"name\":\"Day Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"09:00\",\"to\":\"17:00\"}},{\"name\":\"Night Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"20:00\",\"to\":\"02:00\"}}],\"meta\":{\"total\":3,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name: "availability within",
+			body: `{"from": "07:00", "to": "13:00", "match": "within"}`,
+			setupFetcherMock: func(mock *DataFetcherMock) {
+				mock.On("GetClinicData", m.Anything, m.Anything).
+					Return([]Clinic{
+						{Name: "Morning Clinic", State: "FL", Availability: Availability{From: "08:00", To: "12:00"}},
+						{Name: "Day Clinic", State: "FL", Availability: Availability{From: "09:00", To: "17:00"}},
+						{Name: "Night Clinic", State: "FL", Availability: Availability{From: "20:00", To: "02:00"}},
+					}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Morning Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"08:00\",\"to\":\"12:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name: "availability covers past midnight",
+			body: `{"from": "01:00", "to": "01:30"}`,
+			setupFetcherMock: func(mock *DataFetcherMock) {
+				mock.On("GetClinicData", m.Anything, m.Anything).
+					Return([]Clinic{
+						{Name: "Morning Clinic", State: "FL", Availability: Availability{From: "08:00", To: "12:00"}},
+						{Name: "Day Clinic", State: "FL", Availability: Availability{From: "09:00", To: "17:00"}},
+						{Name: "Night Clinic", State: "FL", Availability: Availability{From: "20:00", To: "02:00"}},
+					}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Night Clinic\",\"state\":\"FL\",\"availability\":{\"from\":\"20:00\",\"to\":\"02:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
 		},
 	}
 	for _, tt := range tests {
@@ -295,7 +409,7 @@ func TestSearch(t *testing.T) {
 			response := httptest.NewRecorder()
 
 			r := chi.NewRouter()
-			r.Post("/v1/clinics/search", Search(fetcherMock))
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
 			r.ServeHTTP(response, request)
 
 			body, _ := ioutil.ReadAll(response.Body)
@@ -306,3 +420,602 @@ func TestSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchValidatesConcurrently(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/v1/clinics/search", Search(NewSnapshotStore(&DataFetcherMock{}, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search", strings.NewReader(`{"state": "Atlantis"}`))
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Contains(t, response.Body.String(), `"state[0]":"state[0] must be a known state code or name"`)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestPagerBounds(t *testing.T) {
+	tests := []struct {
+		pager      httputil.Pager
+		start, end int
+	}{
+		{pager: httputil.Pager{Page: 2, Size: 10, OffSet: 10}, start: 10, end: 20},
+		{pager: httputil.Pager{Page: 3, Size: 10, OffSet: 20}, start: 20, end: 25},
+		{pager: httputil.Pager{Page: 9, Size: 10, OffSet: 80}, start: 25, end: 25},
+		{pager: httputil.Pager{Page: 1, Size: 10, OffSet: -10}, start: 0, end: 10},
+	}
+
+	for _, tt := range tests {
+		start, end := tt.pager.Bounds(25)
+		assert.Equal(t, tt.start, start, "start of offset %d", tt.pager.OffSet)
+		assert.Equal(t, tt.end, end, "end of offset %d", tt.pager.OffSet)
+	}
+}
+
+func TestGetSortAndPagination(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Availability: Availability{From: "15:00", To: "22:30"}},
+		{Name: "German Pets Clinics", State: "KS", Availability: Availability{From: "08:00", To: "20:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantBody string
+		wantLink string
+	}{
+		{
+			name:     "unsupported sort field",
+			query:    "?sort=-city",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid sort params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"sort\":\"sort field \\\"city\\\" is not supported\"}}\n",
+		},
+		{
+			name:     "page overflowing the offset",
+			query:    "?page=9223372036854775807&size=10",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid page params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"page\":\"page must start within the first 1000000 items\"}}\n",
+		},
+		{
+			name:     "page beyond the maximum offset",
+			query:    "?page=20002&size=50",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid page params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"page\":\"page must start within the first 1000000 items\"}}\n",
+		},
+		{
+			name:     "sorts descending by name",
+			query:    "?sort=-name&size=1",
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"National Veterinary Clinic\",\"state\":\"CA\",\"availability\":{\"from\":\"15:00\",\"to\":\"22:30\"}}],\"meta\":{\"total\":3,\"page\":1,\"size\":1,\"total_pages\":3,\"next_cursor\":\"eyJ2IjoxLCJkIjoiODY1NDNhYmI0NDNhZjRmYiIsInMiOiItbmFtZSIsIm8iOjF9.XMEQ8SlrPbzvSZsPVtQE3ndg65VdXy3dGDYq1tFLMgI\"}}\n",
+			wantLink: "</v1/clinics/?page=2&size=1&sort=-name>; rel=\"next\", </v1/clinics/?page=1&size=1&sort=-name>; rel=\"first\", </v1/clinics/?page=3&size=1&sort=-name>; rel=\"last\"",
+		},
+		{
+			name:     "sorts by multiple fields",
+			query:    "?sort=from,-state&page=2&size=1",
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Good Health Home\",\"state\":\"FL\",\"availability\":{\"from\":\"15:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":3,\"page\":2,\"size\":1,\"total_pages\":3,\"next_cursor\":\"eyJ2IjoxLCJkIjoiODY1NDNhYmI0NDNhZjRmYiIsInMiOiJmcm9tLC1zdGF0ZSIsIm8iOjJ9.BYyX9XxPVgiRvpuGAeINvvWGYW-tPddK1N4I7xGIIAI\"}}\n",
+			wantLink: "</v1/clinics/?page=3&size=1&sort=from%2C-state>; rel=\"next\", </v1/clinics/?page=1&size=1&sort=from%2C-state>; rel=\"prev\", </v1/clinics/?page=1&size=1&sort=from%2C-state>; rel=\"first\", </v1/clinics/?page=3&size=1&sort=from%2C-state>; rel=\"last\"",
+		},
+		{
+			name:     "projects the requested fields",
+			query:    "?fields=name,availability.from&sort=name&size=2",
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"availability\":{\"from\":\"08:00\"},\"name\":\"German Pets Clinics\"},{\"availability\":{\"from\":\"15:00\"},\"name\":\"Good Health Home\"}],\"meta\":{\"total\":3,\"page\":1,\"size\":2,\"total_pages\":2,\"next_cursor\":\"eyJ2IjoxLCJkIjoiODY1NDNhYmI0NDNhZjRmYiIsInMiOiJuYW1lIiwibyI6Mn0.qrBe7lPlAHzoZvmlK1mPu9KSVZ9DrOe8GhfHlC1MfNk\"}}\n",
+			wantLink: "</v1/clinics/?fields=name%2Cavailability.from&page=2&size=2&sort=name>; rel=\"next\", </v1/clinics/?fields=name%2Cavailability.from&page=1&size=2&sort=name>; rel=\"first\", </v1/clinics/?fields=name%2Cavailability.from&page=2&size=2&sort=name>; rel=\"last\"",
+		},
+		{
+			name:     "rejects unknown fields",
+			query:    "?fields=name,zip,availability.days",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid fields params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"fields\":\"unknown fields \\\"zip\\\", \\\"availability.days\\\"\"}}\n",
+		},
+		{
+			name:     "page past the end is empty",
+			query:    "?page=5&size=2",
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":3,\"page\":5,\"size\":2,\"total_pages\":2}}\n",
+			wantLink: "</v1/clinics/?page=2&size=2>; rel=\"prev\", </v1/clinics/?page=1&size=2>; rel=\"first\", </v1/clinics/?page=2&size=2>; rel=\"last\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(append([]Clinic(nil), clinics...), nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/"+tt.query, nil)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+			r.ServeHTTP(response, request)
+
+			body, _ := ioutil.ReadAll(response.Body)
+
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantLink, response.Header().Get("Link"))
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	first := []Clinic{
+		{Name: "Good Health Home", State: "FL", Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Availability: Availability{From: "15:00", To: "22:30"}},
+		{Name: "German Pets Clinics", State: "KS", Availability: Availability{From: "08:00", To: "20:00"}},
+	}
+	refreshed := []Clinic{
+		{Name: "Another Clinic", State: "NV", Availability: Availability{From: "10:00", To: "18:00"}},
+	}
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(first, nil).Once()
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(refreshed, nil).Once()
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(first[:1], nil).Twice()
+
+	store := NewSnapshotStore(fetcherMock, 0, 2)
+
+	r := chi.NewRouter()
+	r.Get("/v1/clinics/", GetAllClinics(store, testCursors))
+
+	type clinicList struct {
+		Data []Clinic
+		Meta httputil.PageMeta
+	}
+
+	get := func(url string) (*httptest.ResponseRecorder, clinicList) {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, httptest.NewRequest(http.MethodGet, url, nil))
+
+		var list clinicList
+		_ = json.Unmarshal(response.Body.Bytes(), &list)
+
+		return response, list
+	}
+
+	response, page := get("http://www.test.com/v1/clinics/?sort=name&size=2")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, []Clinic{first[2], first[0]}, page.Data)
+	assert.NotEmpty(t, page.Meta.NextCursor)
+
+	// the snapshot is refreshed with different data but the cursor keeps reading the version it was issued for
+	_, current := get("http://www.test.com/v1/clinics/")
+	assert.Equal(t, refreshed, current.Data)
+
+	response, next := get("http://www.test.com/v1/clinics/?size=2&cursor=" + page.Meta.NextCursor)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, []Clinic{first[1]}, next.Data)
+	assert.Equal(t, 3, next.Meta.Total)
+	assert.Empty(t, next.Meta.NextCursor)
+	assert.Equal(t, "3", response.Header().Get("X-Total-Count"))
+
+	response, _ = get("http://www.test.com/v1/clinics/?size=2&cursor=" + page.Meta.NextCursor + "x")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "{\"error\":\"invalid cursor\",\"code\":\"LIST_INVALID_CURSOR\",\"messages\":{\"cursor\":\"cursor is invalid or does not belong to this query\"}}\n", response.Body.String())
+
+	// a third version drops the first one as only two are retained,
+	// the expired cursor is checked against the current data which differs from its data
+	get("http://www.test.com/v1/clinics/")
+
+	response, _ = get("http://www.test.com/v1/clinics/?size=2&cursor=" + page.Meta.NextCursor)
+	assert.Equal(t, http.StatusGone, response.Code)
+	assert.True(t, fetcherMock.AssertExpectations(t))
+	assert.Equal(t, "{\"error\":\"cursor expired\",\"code\":\"LIST_CURSOR_EXPIRED\",\"messages\":{\"cursor\":\"the data this cursor was issued for is no longer available, restart from the first page\"}}\n", response.Body.String())
+}
+
+func TestGetFacets(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet, Availability: Availability{From: "15:30", To: "22:30"}},
+		{Name: "German Pets Clinics", State: "KS", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Mayo Clinic", State: "FL", Type: TypeDental, Availability: Availability{From: "", To: ""}},
+		{Name: "Night Owl Vets", State: "Kansas", Type: TypeVet, Availability: Availability{From: "24:00", To: "06:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "counts every facet",
+			query:    "",
+			wantCode: http.StatusOK,
+			wantBody: "{\"total\":5,\"facets\":{\"opens\":{\"00:00-01:00\":1,\"08:00-09:00\":1,\"15:00-16:00\":2,\"unknown\":1},\"state\":{\"CA\":1,\"FL\":2,\"KS\":2},\"type\":{\"dental\":2,\"vet\":3}}}\n",
+		},
+		{
+			name:     "counts the clinics matching the filters",
+			query:    "?q=type:vet&from=16:00&to=19:00",
+			wantCode: http.StatusOK,
+			wantBody: "{\"total\":2,\"facets\":{\"opens\":{\"08:00-09:00\":1,\"15:00-16:00\":1},\"state\":{\"CA\":1,\"KS\":1},\"type\":{\"vet\":2}}}\n",
+		},
+		{
+			name:     "only counts",
+			query:    "?state=FL&count_only=true",
+			wantCode: http.StatusOK,
+			wantBody: "{\"total\":2}\n",
+		},
+		{
+			name:     "invalid count only",
+			query:    "?count_only=maybe",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"count_only\":\"count_only must be a boolean\"}}\n",
+		},
+		{
+			name:     "invalid filters",
+			query:    "?q=type:",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid query\",\"code\":\"SEARCH_INVALID_QUERY\",\"messages\":{\"q\":\"syntax error at position 6: expected a value for field 'type' but found end of query\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/facets"+tt.query, nil)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/v1/clinics/facets", GetFacets(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms))
+			r.ServeHTTP(response, request)
+
+			body, _ := ioutil.ReadAll(response.Body)
+
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestSearchMultiValueFilters(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+		{Name: "German Pets Clinics", State: "KS", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Desert Dental", State: "NV", Type: TypeDental, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Tar Heel Dental", State: "North Carolina", Type: TypeDental, Availability: Availability{From: "09:00", To: "17:00"}},
+		{Name: "Silver State Vets", State: "Nevada", Type: TypeVet, Availability: Availability{From: "09:00", To: "17:00"}},
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		query     string
+		body      string
+		wantCode  int
+		wantNames []string
+		wantBody  string
+	}{
+		{
+			name:      "states in the body",
+			method:    http.MethodPost,
+			body:      `{"state": ["CA", "NV", "AZ"]}`,
+			wantCode:  http.StatusOK,
+			wantNames: []string{"National Veterinary Clinic", "Desert Dental", "Silver State Vets"},
+		},
+		{
+			name:      "states are not matched within other state names",
+			method:    http.MethodGet,
+			query:     "?state=CA&state=NV",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"National Veterinary Clinic", "Desert Dental", "Silver State Vets"},
+		},
+		{
+			name:      "states are matched by code and name",
+			method:    http.MethodGet,
+			query:     "?state=NV&state=north%20carolina",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"Desert Dental", "Tar Heel Dental", "Silver State Vets"},
+		},
+		{
+			name:      "single state in the body",
+			method:    http.MethodPost,
+			body:      `{"state": "KS"}`,
+			wantCode:  http.StatusOK,
+			wantNames: []string{"German Pets Clinics"},
+		},
+		{
+			name:      "states and types in the body",
+			method:    http.MethodPost,
+			body:      `{"state": ["CA", "NV", "FL"], "type": ["dental"]}`,
+			wantCode:  http.StatusOK,
+			wantNames: []string{"Good Health Home", "Desert Dental"},
+		},
+		{
+			name:      "repeated query parameters",
+			method:    http.MethodGet,
+			query:     "?state=CA&state=KS&type=vet",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"National Veterinary Clinic", "German Pets Clinics"},
+		},
+		{
+			name:     "invalid elements",
+			method:   http.MethodPost,
+			body:     `{"state": ["CA", ""], "type": ["vet", "spa"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"state[1]\":\"state[1] is a required field\",\"type[1]\":\"type[1] must be one of [dental vet]\"}}\n",
+		},
+		{
+			name:     "invalid elements in query parameters",
+			method:   http.MethodGet,
+			query:    "?type=clinic",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"type[0]\":\"type[0] must be one of [dental vet]\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(tt.method, "http://www.test.com/v1/clinics/search"+tt.query, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.Get("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantCode, response.Code)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, response.Body.String())
+				return
+			}
+
+			var list struct {
+				Data []Clinic
+			}
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+
+			var names []string
+			for _, c := range list.Data {
+				names = append(names, c.Name)
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func TestSearchBatch(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+		{Name: "German Pets Clinics", State: "KS", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "not a list of searches",
+			body:     `{"name": "vets"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid json params\",\"code\":\"SEARCH_INVALID_BODY\",\"messages\":{}}\n",
+		},
+		{
+			name:     "empty batch",
+			body:     `[]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid batch\",\"code\":\"BATCH_INVALID\",\"messages\":{\"searches\":\"a batch holds between 1 and 20 searches\"}}\n",
+		},
+		{
+			name:     "missing and duplicate names",
+			body:     `[{"name": "vets"}, {"params": {}}, {"name": "vets"}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid batch\",\"code\":\"BATCH_INVALID\",\"messages\":{\"searches[1]\":\"the name of the search is required\",\"searches[2]\":\"the name \\\"vets\\\" is already used by another search\"}}\n",
+		},
+		{
+			name:     "rejects cursors",
+			query:    "?cursor=eyJ2IjoxLCJvIjoxfQ.jhdoh9ZFWOZHSwlhNSi9qwOtUOM_hyujYu8f_10llMI",
+			body:     `[{"name": "vets", "params": {"type": "vet"}}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid cursor\",\"code\":\"LIST_INVALID_CURSOR\",\"messages\":{\"cursor\":\"cursor is not supported by batch searches, page a search through the search endpoint\"}}\n",
+		},
+		{
+			name:     "runs every search and reports invalid ones",
+			query:    "?sort=name&size=2&fields=name",
+			body:     `[{"name": "vets", "params": {"type": "vet"}}, {"name": "florida", "params": {"state": ["FL"]}}, {"name": "broken", "params": {"type": ["spa"]}}, {"name": "garbled", "params": {"state": 1}}]`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"results\":{" +
+				"\"broken\":{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"type[0]\":\"type[0] must be one of [dental vet]\"}}," +
+				"\"florida\":{\"data\":[{\"name\":\"Good Health Home\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":2,\"total_pages\":1}}," +
+				"\"garbled\":{\"error\":\"invalid json params\",\"code\":\"SEARCH_INVALID_BODY\",\"messages\":{}}," +
+				"\"vets\":{\"data\":[{\"name\":\"German Pets Clinics\"},{\"name\":\"National Veterinary Clinic\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":2,\"total_pages\":1}}}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search/batch"+tt.query, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search/batch", SearchBatch(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestSearchExplain(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "explains the returned clinics",
+			query:    "?explain=true&fields=name",
+			body:     `{"state": ["ca"], "from": "16:00", "to": "18:00"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"National Veterinary Clinic\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"explain\":[" +
+				"{\"clinic\":\"National Veterinary Clinic\",\"matched\":true,\"predicates\":[" +
+				"{\"predicate\":\"in\",\"field\":\"state\",\"value\":\"CA\",\"expected\":\"CA\",\"passed\":true}," +
+				"{\"predicate\":\"covers\",\"field\":\"availability\",\"value\":\"15:00-22:30\",\"expected\":\"16:00-18:00\",\"passed\":true}]}]}\n",
+		},
+		{
+			name:     "explains a clinic by name",
+			query:    "?explain_name=good%20health%20home&fields=name",
+			body:     `{"q": "type:vet OR name:emergency", "to": "23:00", "match": "overlaps"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"National Veterinary Clinic\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"explain\":[" +
+				"{\"clinic\":\"Good Health Home\",\"matched\":false,\"predicates\":[" +
+				"{\"predicate\":\"overlaps\",\"field\":\"availability\",\"value\":\"15:00-20:00\",\"expected\":\"00:00-23:00\",\"passed\":true}," +
+				"{\"predicate\":\"matches\",\"field\":\"q\",\"value\":\"\",\"expected\":\"(type:\\\"vet\\\" OR name:\\\"emergency\\\")\",\"passed\":false}," +
+				"{\"predicate\":\"q term\",\"field\":\"type\",\"value\":\"dental\",\"expected\":\"vet\",\"passed\":false}," +
+				"{\"predicate\":\"q term\",\"field\":\"name\",\"value\":\"good health home\",\"expected\":\"emergency\",\"passed\":false}]}]}\n",
+		},
+		{
+			name:     "invalid explain value",
+			query:    "?explain=maybe",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid explain params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"explain\":\"explain must be a boolean\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search"+tt.query, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestSearchProximity(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "Bay Pets", State: "California", City: "San Francisco", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Desert Dental", State: "NV", Type: TypeDental, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Lost Clinic", State: "Atlantis", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		wantCode      int
+		wantNames     []string
+		wantDistances []interface{}
+		wantBody      string
+	}{
+		{
+			name:          "orders clinics by distance",
+			query:         "?lat=37.80&lon=-122.27",
+			wantCode:      http.StatusOK,
+			wantNames:     []string{"Bay Pets", "Desert Dental", "Good Health Home", "Lost Clinic"},
+			wantDistances: []interface{}{13.6, 522.4, 3940.9, nil},
+		},
+		{
+			name:          "keeps clinics within the radius",
+			query:         "?lat=37.80&lon=-122.27&radius_km=600&type=dental",
+			wantCode:      http.StatusOK,
+			wantNames:     []string{"Desert Dental"},
+			wantDistances: []interface{}{522.4},
+		},
+		{
+			name:          "explicit sort overrides the distance",
+			query:         "?lat=37.80&lon=-122.27&radius_km=600&sort=-name",
+			wantCode:      http.StatusOK,
+			wantNames:     []string{"Desert Dental", "Bay Pets"},
+			wantDistances: []interface{}{522.4, 13.6},
+		},
+		{
+			name:     "invalid coordinates",
+			query:    "?lat=north&lon=-200",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"lat\":\"lat must be a number\"}}\n",
+		},
+		{
+			name:     "coordinates out of range",
+			query:    "?lat=91&lon=-200",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"lat\":\"lat must be 90 or less\",\"lon\":\"lon must be -180 or greater\"}}\n",
+		},
+		{
+			name:     "radius without location",
+			query:    "?radius_km=10",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"radius_km\":\"radius_km requires lat and lon\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/search"+tt.query, nil)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantCode, response.Code)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, response.Body.String())
+				return
+			}
+
+			var list struct {
+				Data []map[string]interface{}
+			}
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+
+			var names []string
+			var distances []interface{}
+			for _, c := range list.Data {
+				names = append(names, c["name"].(string))
+				distances = append(distances, c["distance_km"])
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantDistances, distances)
+		})
+	}
+}
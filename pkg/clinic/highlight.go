@@ -0,0 +1,158 @@
+package clinic
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Highlight holds the regions of the fields of a returned clinic the search matched
+type Highlight struct {
+	Clinic string                    `json:"clinic"`
+	Fields map[string]FieldHighlight `json:"fields"`
+}
+
+// FieldHighlight holds the matched regions of a field as [start, end) character offsets,
+// and the value of the field with the regions wrapped in `<em>` tags, the rest of the value being HTML escaped
+type FieldHighlight struct {
+	Offsets [][2]int `json:"offsets"`
+	Snippet string   `json:"snippet"`
+}
+
+// highlights returns the highlight of each clinic of the page, in the order of the page
+func (f searchFilter) highlights(page []Clinic) []Highlight {
+	highlights := make([]Highlight, 0, len(page))
+	for _, c := range page {
+		highlights = append(highlights, f.highlight(c))
+	}
+
+	return highlights
+}
+
+// highlight finds the regions of the name and state of the clinic matched by the search,
+// the names are lower cased the way the predicates compare them, while the states are compared by code
+// the way stateIn and the state terms of the query compare them
+func (f searchFilter) highlight(c Clinic) Highlight {
+	names := append([]string(nil), f.names...)
+	states := make([]string, 0, len(f.params.State))
+	for _, state := range f.params.State {
+		states = append(states, stateCode(state))
+	}
+
+	if f.query != nil {
+		for _, term := range f.query.terms() {
+			switch term.field {
+			case "name":
+				names = append(names, strings.ToLower(term.value))
+				names = append(names, term.variants...)
+			case "state":
+				states = append(states, stateCode(term.value))
+			}
+		}
+	}
+
+	h := Highlight{Clinic: c.Name, Fields: make(map[string]FieldHighlight)}
+
+	if regions := matchRegions(c.Name, names); len(regions) > 0 {
+		h.Fields["name"] = fieldHighlight(c.Name, regions)
+	}
+
+	if regions := stateRegions(c.State, states); len(regions) > 0 {
+		h.Fields["state"] = fieldHighlight(c.State, regions)
+	}
+
+	return h
+}
+
+// matchRegions returns the merged character regions of the value containing any of the terms, ignoring case
+func matchRegions(value string, terms []string) [][2]int {
+	lower := strings.ToLower(value)
+
+	var regions [][2]int
+
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], term)
+			if i < 0 {
+				break
+			}
+
+			start := offset + i
+			end := start + len(term)
+
+			// lower casing maps each rune to a single rune, the character offsets of both values are the same
+			regions = append(regions, [2]int{utf8.RuneCountInString(lower[:start]), utf8.RuneCountInString(lower[:end])})
+
+			offset = end
+		}
+	}
+
+	return mergeRegions(regions)
+}
+
+// stateRegions returns the region of the whole state value when its code is any of the codes,
+// a state being matched as a whole whether it is stored by code or by name
+func stateRegions(value string, codes []string) [][2]int {
+	code := stateCode(value)
+	for _, c := range codes {
+		if strings.EqualFold(c, code) {
+			return [][2]int{{0, utf8.RuneCountInString(value)}}
+		}
+	}
+
+	return nil
+}
+
+// mergeRegions sorts the regions and merges the ones overlapping or touching each other
+func mergeRegions(regions [][2]int) [][2]int {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i][0] < regions[j][0]
+	})
+
+	merged := [][2]int{regions[0]}
+	for _, r := range regions[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+func fieldHighlight(value string, regions [][2]int) FieldHighlight {
+	runes := []rune(value)
+
+	var snippet strings.Builder
+
+	last := 0
+	for _, r := range regions {
+		snippet.WriteString(html.EscapeString(string(runes[last:r[0]])))
+		snippet.WriteString("<em>")
+		snippet.WriteString(html.EscapeString(string(runes[r[0]:r[1]])))
+		snippet.WriteString("</em>")
+		last = r[1]
+	}
+
+	snippet.WriteString(html.EscapeString(string(runes[last:])))
+
+	return FieldHighlight{
+		Offsets: regions,
+		Snippet: snippet.String(),
+	}
+}
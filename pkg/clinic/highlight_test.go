@@ -0,0 +1,158 @@
+package clinic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+func TestMatchRegions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		terms []string
+		want  [][2]int
+	}{
+		{name: "no match", value: "Good Health Home", terms: []string{"vet"}},
+		{name: "every occurrence ignoring case", value: "Pets and PETS", terms: []string{"pets"}, want: [][2]int{{0, 4}, {9, 13}}},
+		{name: "overlapping terms are merged", value: "Good Health Home", terms: []string{"good he", "health"}, want: [][2]int{{0, 11}}},
+		{name: "offsets count characters", value: "Clínica Dental", terms: []string{"dental"}, want: [][2]int{{8, 14}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchRegions(tt.value, tt.terms))
+		})
+	}
+}
+
+func TestStateRegions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		codes []string
+		want  [][2]int
+	}{
+		{name: "code", value: "CA", codes: []string{"CA"}, want: [][2]int{{0, 2}}},
+		{name: "name matched by code", value: "California", codes: []string{"CA"}, want: [][2]int{{0, 10}}},
+		{name: "code ignoring case", value: "ca", codes: []string{"CA"}, want: [][2]int{{0, 2}}},
+		{name: "other state", value: "California", codes: []string{"FL", "KS"}},
+		{name: "prefix of another state", value: "Arkansas", codes: []string{"KS"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stateRegions(tt.value, tt.codes))
+		})
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "Health & Pets <24h>", State: "California", Type: TypeVet, Availability: Availability{From: "00:00", To: "24:00"}},
+		{Name: "Coastal Dental", State: "CA", Type: TypeDental, Availability: Availability{From: "08:00", To: "18:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "highlights the name and state",
+			query:    "?highlight=true&fields=name",
+			body:     `{"name": "health", "state": ["california", "FL"]}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Good Health Home\"},{\"name\":\"Health \\u0026 Pets \\u003c24h\\u003e\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Good Health Home\",\"fields\":{" +
+				"\"name\":{\"offsets\":[[5,11]],\"snippet\":\"Good \\u003cem\\u003eHealth\\u003c/em\\u003e Home\"}," +
+				"\"state\":{\"offsets\":[[0,2]],\"snippet\":\"\\u003cem\\u003eFL\\u003c/em\\u003e\"}}}," +
+				"{\"clinic\":\"Health \\u0026 Pets \\u003c24h\\u003e\",\"fields\":{" +
+				"\"name\":{\"offsets\":[[0,6]],\"snippet\":\"\\u003cem\\u003eHealth\\u003c/em\\u003e \\u0026amp; Pets \\u0026lt;24h\\u0026gt;\"}," +
+				"\"state\":{\"offsets\":[[0,10]],\"snippet\":\"\\u003cem\\u003eCalifornia\\u003c/em\\u003e\"}}}]}\n",
+		},
+		{
+			name:     "highlights the terms of a query",
+			query:    "?highlight=true&fields=name",
+			body:     `{"q": "state:fl OR name:pets"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Good Health Home\"},{\"name\":\"Health \\u0026 Pets \\u003c24h\\u003e\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Good Health Home\",\"fields\":{" +
+				"\"state\":{\"offsets\":[[0,2]],\"snippet\":\"\\u003cem\\u003eFL\\u003c/em\\u003e\"}}}," +
+				"{\"clinic\":\"Health \\u0026 Pets \\u003c24h\\u003e\",\"fields\":{" +
+				"\"name\":{\"offsets\":[[9,13]],\"snippet\":\"Health \\u0026amp; \\u003cem\\u003ePets\\u003c/em\\u003e \\u0026lt;24h\\u0026gt;\"}}}]}\n",
+		},
+		{
+			name:     "highlights a state given by code on clinics naming it",
+			query:    "?highlight=true&fields=name",
+			body:     `{"state": "CA"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Health \\u0026 Pets \\u003c24h\\u003e\"},{\"name\":\"Coastal Dental\"}],\"meta\":{\"total\":2,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Health \\u0026 Pets \\u003c24h\\u003e\",\"fields\":{" +
+				"\"state\":{\"offsets\":[[0,10]],\"snippet\":\"\\u003cem\\u003eCalifornia\\u003c/em\\u003e\"}}}," +
+				"{\"clinic\":\"Coastal Dental\",\"fields\":{" +
+				"\"state\":{\"offsets\":[[0,2]],\"snippet\":\"\\u003cem\\u003eCA\\u003c/em\\u003e\"}}}]}\n",
+		},
+		{
+			name:     "highlights a state given by name on clinics coding it",
+			query:    "?highlight=true&fields=name",
+			body:     `{"state": "California", "type": "dental"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Coastal Dental\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Coastal Dental\",\"fields\":{" +
+				"\"state\":{\"offsets\":[[0,2]],\"snippet\":\"\\u003cem\\u003eCA\\u003c/em\\u003e\"}}}]}\n",
+		},
+		{
+			name:     "highlights the state terms of a query by code",
+			query:    "?highlight=true&fields=name",
+			body:     `{"q": "state:ca AND name:pets"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Health \\u0026 Pets \\u003c24h\\u003e\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Health \\u0026 Pets \\u003c24h\\u003e\",\"fields\":{" +
+				"\"name\":{\"offsets\":[[9,13]],\"snippet\":\"Health \\u0026amp; \\u003cem\\u003ePets\\u003c/em\\u003e \\u0026lt;24h\\u0026gt;\"}," +
+				"\"state\":{\"offsets\":[[0,10]],\"snippet\":\"\\u003cem\\u003eCalifornia\\u003c/em\\u003e\"}}}]}\n",
+		},
+		{
+			name:     "highlights the state terms of a query given by name",
+			query:    "?highlight=true&fields=name",
+			body:     `{"q": "state:California AND name:coastal"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Coastal Dental\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"highlight\":[" +
+				"{\"clinic\":\"Coastal Dental\",\"fields\":{" +
+				"\"name\":{\"offsets\":[[0,7]],\"snippet\":\"\\u003cem\\u003eCoastal\\u003c/em\\u003e Dental\"}," +
+				"\"state\":{\"offsets\":[[0,2]],\"snippet\":\"\\u003cem\\u003eCA\\u003c/em\\u003e\"}}}]}\n",
+		},
+		{
+			name:     "invalid highlight value",
+			query:    "?highlight=yes please",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid highlight params\",\"code\":\"LIST_INVALID_PARAMS\",\"messages\":{\"highlight\":\"highlight must be a boolean\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search"+strings.Replace(tt.query, " ", "%20", -1), strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package clinic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+// viewCount returns the count of the row of the view with the given tags, 0 when there is none
+func viewCount(t *testing.T, name string, tags map[string]string) int64 {
+	rows, err := view.RetrieveData(name)
+	assert.NoError(t, err)
+
+	for _, row := range rows {
+		if len(row.Tags) != len(tags) {
+			continue
+		}
+
+		matches := true
+		for _, tg := range row.Tags {
+			if tags[tg.Key.Name()] != tg.Value {
+				matches = false
+			}
+		}
+
+		if !matches {
+			continue
+		}
+
+		switch data := row.Data.(type) {
+		case *view.CountData:
+			return data.Value
+		case *view.DistributionData:
+			return data.Count
+		}
+	}
+
+	return 0
+}
+
+func TestServerMetrics(t *testing.T) {
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil).Maybe()
+
+	routes := chi.NewRouter()
+	routes.Route("/v1/clinics", func(r chi.Router) {
+		r.Get("/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+	})
+
+	mux := httputil.NewBaseMux(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`), httputil.NewAuthenticator(nil, false))
+	mux.Handle("/", routes)
+
+	handler := httputil.Metrics(mux)
+
+	tests := []struct {
+		method   string
+		url      string
+		debugKey bool
+		tags     map[string]string
+	}{
+		{url: "/v1/clinics/?size=1", tags: map[string]string{"http_route": "/v1/clinics/", "http_method": "GET", "http_status": "200"}},
+		{url: "/v1/clinics/?sort=zip", tags: map[string]string{"http_route": "/v1/clinics/", "http_method": "GET", "http_status": "400"}},
+		{url: "/alive", tags: map[string]string{"http_route": "/alive", "http_method": "GET", "http_status": "200"}},
+		{url: "/v1/unknown/42", tags: map[string]string{"http_route": "unmatched", "http_method": "GET", "http_status": "404"}},
+		{url: "/v1/clinics/unknown", tags: map[string]string{"http_route": "unmatched", "http_method": "GET", "http_status": "404"}},
+		{url: "/v1/clinics/foo/bar", tags: map[string]string{"http_route": "unmatched", "http_method": "GET", "http_status": "404"}},
+		{method: http.MethodDelete, url: "/v1/clinics/", tags: map[string]string{"http_route": "/v1/clinics/", "http_method": "DELETE", "http_status": "405"}},
+		{url: "/debug/pprof/cmdline", debugKey: true, tags: map[string]string{"http_route": "/debug/pprof/cmdline", "http_method": "GET", "http_status": "200"}},
+		{url: "/debug/unknown/42", debugKey: true, tags: map[string]string{"http_route": "/debug/", "http_method": "GET", "http_status": "404"}},
+		{url: "/debug/unknown/42", tags: map[string]string{"http_route": "/debug/", "http_method": "GET", "http_status": "401"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if tt.method == "" {
+				tt.method = http.MethodGet
+			}
+
+			counts := make(map[string]int64)
+			for _, v := range httputil.ServerViews {
+				counts[v.Name] = viewCount(t, v.Name, tt.tags)
+			}
+
+			response := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, "http://www.test.com"+tt.url, nil)
+			if tt.debugKey {
+				request = withDebugKey(request)
+			}
+
+			handler.ServeHTTP(response, request)
+
+			for _, v := range httputil.ServerViews {
+				assert.Equal(t, counts[v.Name]+1, viewCount(t, v.Name, tt.tags), v.Name)
+			}
+		})
+	}
+}
+
+func TestProviderMetrics(t *testing.T) {
+	downloader := NewDataDownloader(zap.NewNop(), time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+
+	okTags := map[string]string{"provider": providerVet, "http_status": "200"}
+	errorTags := map[string]string{"provider": providerVet, "http_status": "error"}
+
+	okCount, errorCount := viewCount(t, "clinic/provider/request_count", okTags), viewCount(t, "clinic/provider/request_count", errorTags)
+
+	_, err := downloader.fetchData(context.Background(), providerVet, server.URL)
+	assert.NoError(t, err)
+
+	server.Close()
+
+	_, err = downloader.fetchData(context.Background(), providerVet, server.URL)
+	assert.Error(t, err)
+
+	assert.Equal(t, okCount+1, viewCount(t, "clinic/provider/request_count", okTags))
+	assert.Equal(t, errorCount+1, viewCount(t, "clinic/provider/request_count", errorTags))
+	assert.Equal(t, okCount+1, viewCount(t, "clinic/provider/latency", okTags))
+	assert.Equal(t, okCount+1, viewCount(t, "clinic/provider/response_bytes", okTags))
+}
+
+func TestStatsz(t *testing.T) {
+	view.SetReportingPeriod(10 * time.Millisecond)
+	defer view.SetReportingPeriod(0)
+
+	mux := httputil.NewBaseMux(httputil.TextHandler(http.StatusOK, "application/json", `"READY"`), httputil.NewAuthenticator(nil, false))
+	handler := httputil.Metrics(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://www.test.com/alive", nil))
+
+	assert.Eventually(t, func() bool {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, withDebugKey(httptest.NewRequest(http.MethodGet, "http://www.test.com/debug/statsz", nil)))
+
+		body, _ := ioutil.ReadAll(response.Body)
+
+		return response.Code == http.StatusOK &&
+			response.Header().Get("Content-Type") == "text/plain; charset=utf-8" &&
+			strings.Contains(string(body), "http/server/request_count: Number of requests served by route, method and status (Count, 1)\n") &&
+			strings.Contains(string(body), `  http_method="GET" http_route="/alive" http_status="200" count=`)
+	}, time.Second, 10*time.Millisecond)
+}
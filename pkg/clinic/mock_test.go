@@ -5,6 +5,8 @@
 package clinic
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 )
@@ -14,13 +16,13 @@ type DataFetcherMock struct {
 	mock.Mock
 }
 
-// GetClinicData provides a mock function with given logger field
-func (_m *DataFetcherMock) GetClinicData(logger *zap.Logger) ([]Clinic, error) {
-	ret := _m.Called(logger)
+// GetClinicData provides a mock function with given fields: ctx, logger
+func (_m *DataFetcherMock) GetClinicData(ctx context.Context, logger *zap.Logger) ([]Clinic, error) {
+	ret := _m.Called(ctx, logger)
 
 	var r0 []Clinic
-	if rf, ok := ret.Get(0).(func(*zap.Logger) []Clinic); ok {
-		r0 = rf(logger)
+	if rf, ok := ret.Get(0).(func(context.Context, *zap.Logger) []Clinic); ok {
+		r0 = rf(ctx, logger)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]Clinic)
@@ -28,8 +30,8 @@ func (_m *DataFetcherMock) GetClinicData(logger *zap.Logger) ([]Clinic, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*zap.Logger) error); ok {
-		r1 = rf(logger)
+	if rf, ok := ret.Get(1).(func(context.Context, *zap.Logger) error); ok {
+		r1 = rf(ctx, logger)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,10 +1,28 @@
 package clinic
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/scratchpay_ademola/internal/httputil"
+)
+
+// The types of clinics, after the provider they are fetched from
+const (
+	TypeDental = "dental"
+	TypeVet    = "vet"
+)
+
 // Clinic represents the structure of both the dental and vet clinics
 type Clinic struct {
 	Name         string       `json:"name"`
 	State        string       `json:"state"`
+	City         string       `json:"city,omitempty"`
+	Type         string       `json:"type,omitempty"`
 	Availability Availability `json:"availability"`
+	// DistanceKm is the approximate distance to the location of a proximity search
+	DistanceKm *float64 `json:"distance_km,omitempty"`
 }
 
 // Availability contains the period during which a clinic is available
@@ -16,18 +34,93 @@ type Availability struct {
 type DentalClinic struct {
 	Name         string       `json:"name"`
 	State        string       `json:"stateName"`
+	City         string       `json:"city"`
 	Availability Availability `json:"availability"`
 }
 
 type VetClinic struct {
 	Name         string       `json:"clinicName"`
 	State        string       `json:"stateCode"`
+	City         string       `json:"city"`
 	Availability Availability `json:"opening"`
 }
 
 type SearchParams struct {
-	Name  string `json:"name"`
-	State string `json:"state"`
-	From  string `json:"from"`
-	To    string `json:"to"`
+	Name  string     `json:"name" validate:"max=100"`
+	State StringList `json:"state" validate:"max=20,dive,required,max=50,usstate"`
+	Type  StringList `json:"type" validate:"max=20,dive,oneof=dental vet"`
+	From  string     `json:"from" validate:"omitempty,clock,beforetime=to"`
+	To    string     `json:"to" validate:"omitempty,clock"`
+	Match string     `json:"match"`
+	Q     string     `json:"q" validate:"max=500"`
+
+	Lat      *float64 `json:"lat,omitempty" validate:"omitempty,min=-90,max=90"`
+	Lon      *float64 `json:"lon,omitempty" validate:"omitempty,min=-180,max=180"`
+	RadiusKm float64  `json:"radius_km,omitempty" validate:"omitempty,gt=0,max=20000"`
+}
+
+// BatchSearch is a named search of a batch
+type BatchSearch struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params"`
+}
+
+// BatchSearchResult is the outcome of a search of a batch, either its page of clinics or the reason it is invalid
+type BatchSearchResult struct {
+	*ListResponse
+	*httputil.Response
+}
+
+// BatchSearchResponse holds the result of every search of a batch keyed by the search name
+type BatchSearchResponse struct {
+	Results map[string]BatchSearchResult `json:"results"`
+}
+
+// StringList is a list of strings that can also be given as a single string in JSON
+type StringList []string
+
+// UnmarshalJSON decodes either a string or an array of strings, an empty string is an empty list
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*l = nil
+		if value != "" {
+			*l = StringList{value}
+		}
+
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*l = values
+
+	return nil
+}
+
+// fingerprint identifies the filters of a search
+func (p SearchParams) fingerprint() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// SynonymsReloadResponse reports whether the synonym dictionary was reloaded, it is not when the file is unchanged
+type SynonymsReloadResponse struct {
+	Reloaded bool `json:"reloaded"`
+}
+
+// ListResponse is a page of clinics along with the pagination metadata,
+// Data holds the clinics reduced to the requested fields when a sparse fieldset is asked for
+type ListResponse struct {
+	Data      interface{}       `json:"data"`
+	Meta      httputil.PageMeta `json:"meta"`
+	Explain   []Explanation     `json:"explain,omitempty"`
+	Highlight []Highlight       `json:"highlight,omitempty"`
+	// Suggestions are the spelling suggestions by field of a search matching no clinic
+	Suggestions map[string][]string `json:"suggestions,omitempty"`
 }
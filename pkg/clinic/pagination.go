@@ -0,0 +1,317 @@
+package clinic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/scratchpay_ademola/internal/validatorutil"
+	"go.uber.org/zap"
+)
+
+var (
+	// errInvalidCursor is returned when a cursor was tampered with or issued for another query
+	errInvalidCursor = errors.New("invalid cursor")
+
+	// errCursorExpired is returned when the snapshot a cursor was issued for is no longer retained
+	errCursorExpired = errors.New("cursor expired")
+)
+
+// paramError is returned when a listing query parameter holds an unsupported value
+type paramError struct {
+	param string
+	err   error
+}
+
+func (e paramError) Error() string {
+	return e.err.Error()
+}
+
+// cursor is the state carried by the `next_cursor` token between the pages of a listing.
+//
+// It pins the snapshot version and sort order the listing started with,
+// so the following pages are read from the same immutable data in the same order.
+// The version restarts with the process and differs between instances, so the cursor also carries
+// the ID of the data it was issued for and is only accepted by a snapshot holding that data.
+type cursor struct {
+	Version uint64 `json:"v"`
+	Data    string `json:"d"`
+	Sort    string `json:"s,omitempty"`
+	Query   string `json:"q,omitempty"`
+	Offset  int    `json:"o"`
+}
+
+// pageRequest is a page of clinics requested either by page number or by a cursor,
+// along with its order, the fields of each clinic and its format
+type pageRequest struct {
+	pager   httputil.Pager
+	sorting []httputil.SortField
+	fields  []string
+	cursor  *cursor
+	query   string
+	format  httputil.Format
+}
+
+// getPageRequest reads the pagination, sorting and fields params of the request,
+// query identifies the filters of the listing so that a cursor cannot be replayed against another one
+func getPageRequest(r *http.Request, cursors *httputil.CursorCodec, query string) (pageRequest, error) {
+	req := pageRequest{
+		sorting: httputil.GetSort(r),
+		fields:  httputil.GetFields(r),
+		query:   query,
+	}
+
+	pager, err := httputil.GetPager(r)
+	if err != nil {
+		return req, paramError{"page", err}
+	}
+	req.pager = pager
+
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		var c cursor
+		if err := cursors.Decode(token, &c); err != nil || c.Query != query || c.Offset < 0 {
+			return req, errInvalidCursor
+		}
+
+		req.cursor = &c
+		req.sorting = httputil.ParseSort(c.Sort)
+		req.pager = httputil.Pager{
+			Page:   c.Offset/req.pager.Size + 1,
+			Size:   req.pager.Size,
+			OffSet: c.Offset,
+		}
+	}
+
+	if err := validateSort(req.sorting); err != nil {
+		return req, paramError{"sort", err}
+	}
+
+	if err := httputil.ValidateFields(reflect.TypeOf(Clinic{}), req.fields); err != nil {
+		return req, paramError{"fields", err}
+	}
+
+	return req, nil
+}
+
+// snapshot returns the snapshot the page is read from, the one holding the data pinned by the cursor or else the current one
+func (p pageRequest) snapshot(ctx context.Context, store *SnapshotStore, l *zap.Logger) (*Snapshot, error) {
+	if p.cursor == nil {
+		return store.Current(ctx, l)
+	}
+
+	if snapshot, ok := store.Version(p.cursor.Version); ok && snapshot.dataID() == p.cursor.Data {
+		return snapshot, nil
+	}
+
+	// the cursor may have been issued by another instance or before a restart,
+	// it still holds against the current snapshot when that one holds the same data
+	snapshot, err := store.Current(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.dataID() != p.cursor.Data {
+		return nil, errCursorExpired
+	}
+
+	return snapshot, nil
+}
+
+// listFormats are the formats a list of clinics is rendered in, JSON being the default
+var listFormats = []httputil.Format{httputil.FormatJSON, httputil.FormatCSV, httputil.FormatNDJSON}
+
+// listFormat negotiates the format of a list of clinics from the `format` param and the `Accept` header.
+// The response varies by the `Accept` header whatever the outcome, errors included.
+func listFormat(w http.ResponseWriter, r *http.Request) (httputil.Format, error) {
+	w.Header().Add("Vary", "Accept")
+
+	return httputil.NegotiateFormat(r, listFormats...)
+}
+
+// boolParam reads an optional boolean query parameter, false when it is missing
+func boolParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, paramError{name, fmt.Errorf("%s must be a boolean", name)}
+	}
+
+	return b, nil
+}
+
+// writePageError renders the error returned while reading the page request or loading its snapshot
+func writePageError(w http.ResponseWriter, r *http.Request, l *zap.Logger, err error, attrErrMessages map[string]string) {
+	if paramErr, ok := err.(paramError); ok {
+		attrErrMessages[paramErr.param] = paramErr.Error()
+		httputil.JSONError(w, r, codeInvalidListParams.WithMessage(fmt.Sprintf("invalid %s params", paramErr.param)), attrErrMessages)
+		return
+	}
+
+	switch err {
+	case httputil.ErrNotAcceptable:
+		types := make([]string, len(listFormats))
+		for i, f := range listFormats {
+			types[i] = f.MediaType()
+		}
+
+		attrErrMessages["format"] = fmt.Sprintf("the supported formats are %s", strings.Join(types, ", "))
+		httputil.JSONError(w, r, codeNotAcceptable, attrErrMessages)
+		return
+	case errInvalidCursor:
+		attrErrMessages["cursor"] = "cursor is invalid or does not belong to this query"
+		httputil.JSONError(w, r, codeInvalidCursor, attrErrMessages)
+		return
+	case errCursorExpired:
+		attrErrMessages["cursor"] = "the data this cursor was issued for is no longer available, restart from the first page"
+		httputil.JSONError(w, r, codeCursorExpired, attrErrMessages)
+		return
+	}
+
+	l.Error("error fetching clinic data", zap.Error(err))
+	httputil.JSONError(w, r, codeUpstreamUnavailable, attrErrMessages)
+}
+
+// writeList sorts the clinics and renders the requested page along with its metadata and Link headers
+func writeList(w http.ResponseWriter, r *http.Request, l *zap.Logger, cursors *httputil.CursorCodec, req pageRequest, snapshot *Snapshot, clinics []Clinic) {
+	res, page, err := listPage(cursors, req, snapshot, clinics)
+	if err != nil {
+		l.Error("failed listing clinics", zap.Error(err))
+		httputil.JSONError(w, r, codeListingFailed, validatorutil.GetAttributeErrorMessages())
+		return
+	}
+
+	writeListResponse(w, r, l, req, res, page)
+}
+
+// writeListResponse renders a page of clinics in the format of the request along with its Link headers.
+//
+// CSV and NDJSON hold the clinics of the page only, the pagination is carried by the `X-Total-Count`, `Link`
+// and `X-Next-Cursor` headers.
+func writeListResponse(w http.ResponseWriter, r *http.Request, l *zap.Logger, req pageRequest, res ListResponse, page []Clinic) {
+	if req.cursor != nil {
+		httputil.SetCursorHeaders(w, r, res.Meta.Total, res.Meta.NextCursor)
+	} else {
+		httputil.SetPageHeaders(w, r, req.pager, res.Meta.Total)
+	}
+
+	switch req.format {
+	case httputil.FormatCSV:
+		setNextCursorHeader(w, res.Meta.NextCursor)
+
+		header := httputil.LeafFields(reflect.TypeOf(Clinic{}), req.fields)
+
+		rows := make([][]string, 0, len(page))
+		for _, c := range page {
+			row, err := httputil.Record(c, header)
+			if err != nil {
+				l.Error("failed rendering clinics as csv", zap.Error(err))
+				httputil.JSONError(w, r, codeListingFailed, validatorutil.GetAttributeErrorMessages())
+				return
+			}
+
+			rows = append(rows, row)
+		}
+
+		if err := httputil.CSV(w, http.StatusOK, header, rows); err != nil {
+			l.Error("failed writing csv response", zap.Error(err))
+		}
+	case httputil.FormatNDJSON:
+		setNextCursorHeader(w, res.Meta.NextCursor)
+
+		items := make([]interface{}, 0, len(page))
+		switch data := res.Data.(type) {
+		case []Clinic:
+			for _, c := range data {
+				items = append(items, c)
+			}
+		case []map[string]interface{}:
+			for _, c := range data {
+				items = append(items, c)
+			}
+		}
+
+		if err := httputil.NDJSON(w, http.StatusOK, items); err != nil {
+			l.Error("failed writing ndjson response", zap.Error(err))
+		}
+	default:
+		httputil.JSONSuccess(w, http.StatusOK, res)
+	}
+}
+
+// setNextCursorHeader writes the `X-Next-Cursor` header carrying the `next_cursor` of formats without metadata
+func setNextCursorHeader(w http.ResponseWriter, next string) {
+	if next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+	}
+}
+
+// listPage sorts the clinics and returns the requested page along with its metadata, and the clinics of the page
+func listPage(cursors *httputil.CursorCodec, req pageRequest, snapshot *Snapshot, clinics []Clinic) (ListResponse, []Clinic, error) {
+	sortClinics(clinics, req.sorting)
+
+	total := len(clinics)
+	start, end := req.pager.Bounds(total)
+
+	page := make([]Clinic, 0, end-start)
+	page = append(page, clinics[start:end]...)
+
+	meta := req.pager.Meta(total)
+
+	if end < total {
+		sort := make([]string, len(req.sorting))
+		for i, field := range req.sorting {
+			sort[i] = field.String()
+		}
+
+		next, err := cursors.Encode(cursor{
+			Version: snapshot.Version,
+			Data:    snapshot.dataID(),
+			Sort:    strings.Join(sort, ","),
+			Query:   req.query,
+			Offset:  end,
+		})
+		if err != nil {
+			return ListResponse{}, nil, err
+		}
+
+		meta.NextCursor = next
+	}
+
+	data, err := projectClinics(page, req.fields)
+	if err != nil {
+		return ListResponse{}, nil, err
+	}
+
+	return ListResponse{
+		Data: data,
+		Meta: meta,
+	}, page, nil
+}
+
+// projectClinics reduces each clinic to the given fields, the clinics are returned as is when no fields are given
+func projectClinics(clinics []Clinic, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return clinics, nil
+	}
+
+	projected := make([]map[string]interface{}, 0, len(clinics))
+	for _, c := range clinics {
+		p, err := httputil.Project(c, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		projected = append(projected, p)
+	}
+
+	return projected, nil
+}
@@ -0,0 +1,163 @@
+package clinic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+func TestProblemErrors(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		url             string
+		header          http.Header
+		body            string
+		wantCode        int
+		wantContentType string
+		wantVary        string
+		wantBody        string
+	}{
+		{
+			name:            "legacy errors by default",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search",
+			body:            `{"from": "banana"}`,
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"from\":\"from must be a time in the HH:MM format\"}}\n",
+		},
+		{
+			name:            "problem from the accept header",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search?size=10",
+			header:          http.Header{"Accept": {"application/json, application/problem+json"}},
+			body:            `{"from": "banana"}`,
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"type\":\"/v1/errors#SEARCH_INVALID_ATTRIBUTES\",\"title\":\"invalid attributes\",\"status\":400,\"detail\":\"invalid attributes\",\"instance\":\"/v1/clinics/search?size=10\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"retryable\":false,\"errors\":{\"from\":\"from must be a time in the HH:MM format\"}}\n",
+		},
+		{
+			name:            "problem from the api version",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?cursor=forged",
+			header:          http.Header{"Api-Version": {"2"}},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"type\":\"/v1/errors#LIST_INVALID_CURSOR\",\"title\":\"invalid cursor\",\"status\":400,\"detail\":\"invalid cursor\",\"instance\":\"/v1/clinics/?cursor=forged\",\"code\":\"LIST_INVALID_CURSOR\",\"retryable\":false,\"errors\":{\"cursor\":\"cursor is invalid or does not belong to this query\"}}\n",
+		},
+		{
+			name:            "problem without attribute errors",
+			method:          http.MethodPost,
+			url:             "/v1/clinics/search",
+			header:          http.Header{"Accept": {"application/problem+json"}},
+			body:            `{"name":`,
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"type\":\"/v1/errors#SEARCH_INVALID_BODY\",\"title\":\"invalid json params\",\"status\":400,\"detail\":\"invalid json params\",\"instance\":\"/v1/clinics/search\",\"code\":\"SEARCH_INVALID_BODY\",\"retryable\":false}\n",
+		},
+		{
+			name:            "problem detailing the error code",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?sort=zip",
+			header:          http.Header{"Accept": {"application/problem+json"}},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"type\":\"/v1/errors#LIST_INVALID_PARAMS\",\"title\":\"invalid params\",\"status\":400,\"detail\":\"invalid sort params\",\"instance\":\"/v1/clinics/?sort=zip\",\"code\":\"LIST_INVALID_PARAMS\",\"retryable\":false,\"errors\":{\"sort\":\"sort field \\\"zip\\\" is not supported\"}}\n",
+		},
+		{
+			name:            "first api version keeps legacy errors",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?cursor=forged",
+			header:          http.Header{"Api-Version": {"1"}},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantVary:        "Accept, API-Version",
+			wantBody:        "{\"error\":\"invalid cursor\",\"code\":\"LIST_INVALID_CURSOR\",\"messages\":{\"cursor\":\"cursor is invalid or does not belong to this query\"}}\n",
+		},
+		{
+			name:            "successful responses are unchanged",
+			method:          http.MethodGet,
+			url:             "/v1/clinics/?fields=name",
+			header:          http.Header{"Accept": {"application/problem+json"}},
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantVary:        "Accept",
+			wantBody:        "{\"data\":[{\"name\":\"Good Health Home\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(append([]Clinic(nil), clinics...), nil).Maybe()
+
+			request := httptest.NewRequest(tt.method, "http://www.test.com"+tt.url, strings.NewReader(tt.body))
+			for key, values := range tt.header {
+				request.Header[key] = values
+			}
+
+			response := httptest.NewRecorder()
+
+			store := NewSnapshotStore(fetcherMock, 0, 1)
+
+			r := chi.NewRouter()
+			r.Get("/v1/clinics/", GetAllClinics(store, testCursors))
+			r.Post("/v1/clinics/search", Search(store, NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantContentType, response.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantVary, strings.Join(response.Header().Values("Vary"), ", "))
+		})
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/errors", nil)
+	response := httptest.NewRecorder()
+
+	httputil.ErrorCatalogHandler()(response, request)
+
+	var res struct {
+		Data []httputil.ErrorCode `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	codes := make(map[string]httputil.ErrorCode)
+	for i, c := range res.Data {
+		if i > 0 {
+			assert.True(t, res.Data[i-1].Code < c.Code, "codes are ordered")
+		}
+
+		codes[c.Code] = c
+	}
+
+	assert.Equal(t, codeUpstreamUnavailable, codes["CLINIC_UPSTREAM_UNAVAILABLE"])
+	assert.True(t, codes["CLINIC_UPSTREAM_UNAVAILABLE"].Retryable)
+	assert.Equal(t, http.StatusBadRequest, codes["SEARCH_INVALID_BODY"].Status)
+	assert.Equal(t, "invalid json params", codes["SEARCH_INVALID_BODY"].Message)
+	assert.Len(t, codes, 15)
+
+	assert.Panics(t, func() {
+		httputil.NewErrorCode("SEARCH_INVALID_BODY", http.StatusBadRequest, false, "taken")
+	})
+}
@@ -0,0 +1,431 @@
+package clinic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The query language combines field terms with boolean operators, e.g.
+//
+//   (state:CA OR state:NV) AND type:vet AND NOT name:"emergency"
+//
+// A term is a field name and a value separated by a colon, values containing spaces are double quoted.
+// NOT binds tighter than AND which binds tighter than OR, parentheses group expressions
+// and terms separated by whitespace only are joined with AND.
+// Operators are upper case, so `name:or` is a term.
+// Parentheses and NOT operators nest at most maxQueryDepth levels deep.
+//
+// Terms on `name` match when the value is contained in the clinic name, terms on `state` match the clinics
+// in the state whether it is given by code or name, and terms on `type` match the whole value, all ignoring case.
+
+// maxQueryDepth is the deepest nesting of parentheses and NOT operators a query may have
+const maxQueryDepth = 32
+
+// queryFields maps the fields of the query language to the clinic value they match against
+var queryFields = map[string]struct {
+	value   func(c Clinic) string
+	matches func(value, term string) bool
+}{
+	"name":  {func(c Clinic) string { return c.Name }, containsFold},
+	"state": {func(c Clinic) string { return c.State }, sameState},
+	"type":  {func(c Clinic) string { return c.Type }, strings.EqualFold},
+}
+
+func containsFold(value, term string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(term))
+}
+
+// sameState reports whether both values are the same state, by code or name, see stateCode
+func sameState(value, term string) bool {
+	return strings.EqualFold(stateCode(value), stateCode(term))
+}
+
+// QuerySyntaxError reports an invalid query, Pos is the 1-based character position of the error
+type QuerySyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// queryNode is a node of the syntax tree of a parsed query
+type queryNode interface {
+	// eval reports whether the clinic matches the node
+	eval(c Clinic) bool
+	// terms returns the terms of the node from left to right
+	terms() []termNode
+	// withSynonyms returns the node with the terms on `name` expanded by the synonym dictionary
+	withSynonyms(s *Synonyms) queryNode
+	String() string
+}
+
+type andNode struct {
+	left, right queryNode
+}
+
+func (n andNode) eval(c Clinic) bool {
+	return n.left.eval(c) && n.right.eval(c)
+}
+
+func (n andNode) terms() []termNode {
+	return append(n.left.terms(), n.right.terms()...)
+}
+
+func (n andNode) withSynonyms(s *Synonyms) queryNode {
+	return andNode{n.left.withSynonyms(s), n.right.withSynonyms(s)}
+}
+
+func (n andNode) String() string {
+	return fmt.Sprintf("(%s AND %s)", n.left, n.right)
+}
+
+type orNode struct {
+	left, right queryNode
+}
+
+func (n orNode) eval(c Clinic) bool {
+	return n.left.eval(c) || n.right.eval(c)
+}
+
+func (n orNode) terms() []termNode {
+	return append(n.left.terms(), n.right.terms()...)
+}
+
+func (n orNode) withSynonyms(s *Synonyms) queryNode {
+	return orNode{n.left.withSynonyms(s), n.right.withSynonyms(s)}
+}
+
+func (n orNode) String() string {
+	return fmt.Sprintf("(%s OR %s)", n.left, n.right)
+}
+
+type notNode struct {
+	operand queryNode
+}
+
+func (n notNode) eval(c Clinic) bool {
+	return !n.operand.eval(c)
+}
+
+func (n notNode) terms() []termNode {
+	return n.operand.terms()
+}
+
+func (n notNode) withSynonyms(s *Synonyms) queryNode {
+	return notNode{n.operand.withSynonyms(s)}
+}
+
+func (n notNode) String() string {
+	return fmt.Sprintf("NOT %s", n.operand)
+}
+
+type termNode struct {
+	field string
+	value string
+	// variants are the values the synonym dictionary added to the value
+	variants []string
+}
+
+func (n termNode) eval(c Clinic) bool {
+	field := queryFields[n.field]
+
+	if field.matches(field.value(c), n.value) {
+		return true
+	}
+
+	for _, variant := range n.variants {
+		if field.matches(field.value(c), variant) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n termNode) terms() []termNode {
+	return []termNode{n}
+}
+
+func (n termNode) withSynonyms(s *Synonyms) queryNode {
+	if n.field == "name" {
+		n.variants = s.expand(n.value)[1:]
+	}
+
+	return n
+}
+
+func (n termNode) String() string {
+	values := fmt.Sprintf("%q", n.value)
+	for _, variant := range n.variants {
+		values += fmt.Sprintf("|%q", variant)
+	}
+
+	return fmt.Sprintf("%s:%s", n.field, values)
+}
+
+// parseQuery parses a query into its syntax tree
+func parseQuery(q string) (queryNode, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &QuerySyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenColon
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("%q", t.value)
+	default:
+		return fmt.Sprintf("'%s'", t.value)
+	}
+}
+
+func lexQuery(q string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", pos})
+			i++
+		case r == ':':
+			tokens = append(tokens, token{tokenColon, ":", pos})
+			i++
+		case r == '"':
+			var value strings.Builder
+
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+
+			if i == len(runes) {
+				return nil, &QuerySyntaxError{Pos: pos, Msg: "unterminated quoted value"}
+			}
+
+			tokens = append(tokens, token{tokenString, value.String(), pos})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !isQueryDelimiter(runes[i]) {
+				i++
+			}
+
+			word := string(runes[start:i])
+
+			kind := tokenWord
+			switch word {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+
+			tokens = append(tokens, token{kind, word, pos})
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes) + 1}), nil
+}
+
+func isQueryDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == ':' || r == '"'
+}
+
+// queryParser is a recursive descent parser of the grammar
+//
+//	or      = and { "OR" and }
+//	and     = not { [ "AND" ] not }
+//	not     = "NOT" not | primary
+//	primary = "(" or ")" | field ":" value
+type queryParser struct {
+	tokens []token
+	next   int
+	// depth is the nesting of the parentheses and NOT operators being parsed
+	depth int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.next]
+}
+
+// enter enters a nested expression starting at tok, failing when it is nested deeper than maxQueryDepth,
+// the expression is left by calling leave
+func (p *queryParser) enter(tok token) error {
+	if p.depth == maxQueryDepth {
+		return &QuerySyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expressions are nested deeper than %d levels", maxQueryDepth)}
+	}
+
+	p.depth++
+
+	return nil
+}
+
+func (p *queryParser) leave() {
+	p.depth--
+}
+
+func (p *queryParser) consume() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+
+	return tok
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.consume()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.consume()
+		case tokenWord, tokenNot, tokenLParen:
+			// terms separated by whitespace are implicitly joined with AND
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.peek().kind != tokenNot {
+		return p.parsePrimary()
+	}
+
+	if err := p.enter(p.consume()); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	return notNode{operand}, nil
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.consume()
+
+	switch tok.kind {
+	case tokenLParen:
+		if err := p.enter(tok); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.consume(); closing.kind != tokenRParen {
+			return nil, &QuerySyntaxError{Pos: closing.pos, Msg: fmt.Sprintf("expected ')' but found %s", closing)}
+		}
+
+		return node, nil
+	case tokenWord:
+		if _, ok := queryFields[tok.value]; !ok {
+			return nil, &QuerySyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown field '%s'", tok.value)}
+		}
+
+		if colon := p.consume(); colon.kind != tokenColon {
+			return nil, &QuerySyntaxError{Pos: colon.pos, Msg: fmt.Sprintf("expected ':' after field '%s' but found %s", tok.value, colon)}
+		}
+
+		// the operators are values after a colon, e.g. the state code of Oregon in state:OR
+		switch value := p.consume(); value.kind {
+		case tokenWord, tokenString, tokenAnd, tokenOr, tokenNot:
+			return termNode{field: tok.value, value: value.value}, nil
+		default:
+			return nil, &QuerySyntaxError{Pos: value.pos, Msg: fmt.Sprintf("expected a value for field '%s' but found %s", tok.value, value)}
+		}
+	default:
+		return nil, &QuerySyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term but found %s", tok)}
+	}
+}
@@ -0,0 +1,183 @@
+package clinic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "single term",
+			query: "state:CA",
+			want:  `state:"CA"`,
+		},
+		{
+			name:  "AND binds tighter than OR",
+			query: "state:CA OR state:NV AND type:vet",
+			want:  `(state:"CA" OR (state:"NV" AND type:"vet"))`,
+		},
+		{
+			name:  "parentheses and NOT",
+			query: `(state:CA OR state:NV) AND type:vet AND NOT name:"emergency"`,
+			want:  `(((state:"CA" OR state:"NV") AND type:"vet") AND NOT name:"emergency")`,
+		},
+		{
+			name:  "implicit AND",
+			query: `type:vet NOT state:KS`,
+			want:  `(type:"vet" AND NOT state:"KS")`,
+		},
+		{
+			name:  "quoted values keep spaces and escapes",
+			query: `name:"Good \"Health\" Home"`,
+			want:  `name:"Good \"Health\" Home"`,
+		},
+		{
+			name:  "lower case operators are values",
+			query: `name:or`,
+			want:  `name:"or"`,
+		},
+		{
+			name:  "upper case operators are values after a colon",
+			query: `(state:CA OR state:OR) AND NOT name:AND`,
+			want:  `((state:"CA" OR state:"OR") AND NOT name:"AND")`,
+		},
+		{
+			name:  "operator values keep the operators after them",
+			query: `state:OR OR name:NOT`,
+			want:  `(state:"OR" OR name:"NOT")`,
+		},
+		{
+			name:    "unknown field",
+			query:   "state:CA AND city:Miami",
+			wantErr: "syntax error at position 14: unknown field 'city'",
+		},
+		{
+			name:    "missing colon",
+			query:   "state CA",
+			wantErr: "syntax error at position 7: expected ':' after field 'state' but found 'CA'",
+		},
+		{
+			name:    "missing value",
+			query:   "(state:)",
+			wantErr: "syntax error at position 8: expected a value for field 'state' but found ')'",
+		},
+		{
+			name:    "unbalanced parentheses",
+			query:   "(state:CA OR state:NV",
+			wantErr: "syntax error at position 22: expected ')' but found end of query",
+		},
+		{
+			name:    "dangling operator",
+			query:   "state:CA AND",
+			wantErr: "syntax error at position 13: expected a term but found end of query",
+		},
+		{
+			name:    "unexpected closing parenthesis",
+			query:   "state:CA)",
+			wantErr: "syntax error at position 9: unexpected ')'",
+		},
+		{
+			name:    "unterminated quote",
+			query:   `name:"emergency`,
+			wantErr: "syntax error at position 6: unterminated quoted value",
+		},
+		{
+			name:  "nesting up to the maximum depth",
+			query: strings.Repeat("(", maxQueryDepth) + "state:CA" + strings.Repeat(")", maxQueryDepth),
+			want:  `state:"CA"`,
+		},
+		{
+			name:    "parentheses nested too deep",
+			query:   strings.Repeat("(", maxQueryDepth+1) + "state:CA" + strings.Repeat(")", maxQueryDepth+1),
+			wantErr: "syntax error at position 33: expressions are nested deeper than 32 levels",
+		},
+		{
+			name:    "NOT operators nested too deep",
+			query:   strings.Repeat("NOT ", maxQueryDepth+1) + "state:CA",
+			wantErr: "syntax error at position 129: expressions are nested deeper than 32 levels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseQuery(tt.query)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, node.String())
+		})
+	}
+}
+
+func TestQueryEval(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "National Veterinary Clinic", State: "CA", Type: TypeVet},
+		{Name: "Emergency Vet Care", State: "NV", Type: TypeVet},
+		{Name: "German Pets Clinics", State: "KS", Type: TypeVet},
+		{Name: "Good Health Home", State: "ca", Type: TypeDental},
+		{Name: "Bay Pets", State: "California", Type: TypeVet},
+		{Name: "Tar Heel Dental", State: "North Carolina", Type: TypeDental},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "grouped states of a type excluding a name",
+			query: `(state:CA OR state:NV) AND type:vet AND NOT name:"emergency"`,
+			want:  []string{"National Veterinary Clinic", "Bay Pets"},
+		},
+		{
+			name:  "state matches the state by code or name ignoring case",
+			query: `state:CA`,
+			want:  []string{"National Veterinary Clinic", "Good Health Home", "Bay Pets"},
+		},
+		{
+			name:  "state given by name matches the state by code",
+			query: `state:"north carolina" OR state:Nevada`,
+			want:  []string{"Emergency Vet Care", "Tar Heel Dental"},
+		},
+		{
+			name:  "type matches the whole value ignoring case",
+			query: `type:DENTAL`,
+			want:  []string{"Good Health Home", "Tar Heel Dental"},
+		},
+		{
+			name:  "name matches a part of the value ignoring case",
+			query: `name:clinic`,
+			want:  []string{"National Veterinary Clinic", "German Pets Clinics"},
+		},
+		{
+			name:  "double negation",
+			query: `NOT NOT type:dental`,
+			want:  []string{"Good Health Home", "Tar Heel Dental"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newSearchFilter(SearchParams{Q: tt.query}, nil)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, c := range filter.apply(clinics) {
+				names = append(names, c.Name)
+			}
+
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package clinic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/apikey"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil)
+
+	store := NewSnapshotStore(fetcherMock, 0, 1)
+	limiter := httputil.NewRateLimiter(map[string]httputil.RateLimit{
+		"/v1/clinics/search": {Requests: 2, Period: time.Hour},
+	})
+
+	routes := chi.NewRouter()
+	routes.Route("/v1/clinics", func(r chi.Router) {
+		r = r.With(limiter.Handler)
+
+		r.Post("/search", Search(store, NewResultCache(10, 100), testSynonyms, testCursors))
+		r.Get("/", GetAllClinics(store, testCursors))
+	})
+
+	search := func(remoteAddr, keyID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search", strings.NewReader(`{"state": "FL"}`))
+		request.RemoteAddr = remoteAddr
+		if keyID != "" {
+			request = request.WithContext(apikey.With(request.Context(), apikey.Key{ID: keyID}))
+		}
+
+		response := httptest.NewRecorder()
+		routes.ServeHTTP(response, request)
+
+		return response
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		response := search("192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusOK, response.Code, "request %d", i)
+		assert.Equal(t, "2", response.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, remaining, response.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=3600", response.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, response.Header().Get("Retry-After"))
+	}
+
+	// the client is identified by its IP address, whatever its port
+	response := search("192.0.2.1:4321", "")
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.Equal(t, "0", response.Header().Get("RateLimit-Remaining"))
+
+	retryAfter, err := strconv.Atoi(response.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= 1800, "retry after %d", retryAfter)
+
+	reset, err := strconv.Atoi(response.Header().Get("RateLimit-Reset"))
+	assert.NoError(t, err)
+	assert.True(t, reset > 1800 && reset <= 3600, "reset %d", reset)
+
+	var res httputil.Response
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&res))
+	assert.Equal(t, "RATE_LIMITED", res.Code)
+	assert.Equal(t, "too many requests", res.Errors)
+
+	// other clients and authenticated API keys have their own buckets
+	assert.Equal(t, http.StatusOK, search("192.0.2.2:1234", "").Code)
+	assert.Equal(t, http.StatusOK, search("192.0.2.1:1234", "key-1").Code)
+
+	// routes without a limit are not limited
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	list := httptest.NewRecorder()
+	routes.ServeHTTP(list, request)
+	assert.Equal(t, http.StatusOK, list.Code)
+	assert.Empty(t, list.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,117 @@
+package clinic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/scratchpay_ademola/internal/logger"
+	"github.com/scratchpay_ademola/internal/requestid"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	tests := []struct {
+		name      string
+		requestID string
+		wantID    func(t *testing.T, id string)
+	}{
+		{
+			name:      "echoes the request id of the client",
+			requestID: "client-id_42",
+			wantID: func(t *testing.T, id string) {
+				assert.Equal(t, "client-id_42", id)
+			},
+		},
+		{
+			name: "generates a missing request id",
+			wantID: func(t *testing.T, id string) {
+				assert.Regexp(t, generated, id)
+			},
+		},
+		{
+			name:      "replaces an invalid request id",
+			requestID: "forged id",
+			wantID: func(t *testing.T, id string) {
+				assert.Regexp(t, generated, id)
+			},
+		},
+		{
+			name:      "replaces a request id too long",
+			requestID: strings.Repeat("a", 129),
+			wantID: func(t *testing.T, id string) {
+				assert.Regexp(t, generated, id)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetchedWith string
+
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Run(func(args m.Arguments) {
+				fetchedWith = requestid.From(args.Get(0).(context.Context))
+			}).Return([]Clinic{}, nil)
+
+			request := httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/", nil)
+			if tt.requestID != "" {
+				request.Header.Set(requestid.Header, tt.requestID)
+			}
+
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Use(httputil.RequestID)
+			r.Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+			r.ServeHTTP(response, request)
+
+			id := response.Header().Get(requestid.Header)
+			tt.wantID(t, id)
+			assert.Equal(t, id, fetchedWith, "the snapshot is fetched with the request id")
+			assert.Equal(t, http.StatusOK, response.Code)
+		})
+	}
+}
+
+func TestFetchDataForwardsRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	downloader := NewDataDownloader(zap.NewNop(), time.Second)
+
+	_, err := downloader.fetchData(requestid.With(context.Background(), "abc-123"), providerDental, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc-123", received)
+
+	_, err = downloader.fetchData(context.Background(), providerDental, server.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, received)
+}
+
+func TestLoggerFromRequestID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	logger.From(requestid.With(context.Background(), "abc-123"), logger.WithBase(zap.New(core))).Info("with id")
+	logger.From(context.Background(), logger.WithBase(zap.New(core))).Info("without id")
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"request_id": "abc-123"}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+}
@@ -0,0 +1,370 @@
+package clinic
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/geo"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/scratchpay_ademola/internal/validatorutil"
+)
+
+// searchError is returned when the search params are invalid, it holds the messages of the invalid attributes
+type searchError struct {
+	code     httputil.ErrorCode
+	messages map[string]string
+}
+
+func (e searchError) Error() string {
+	return e.code.Message
+}
+
+// predicate is a condition of a search a clinic has to pass to be returned.
+//
+// The values compared by a predicate are normalized the way the condition compares them,
+// e.g. lower cased for case insensitive conditions, so they can be reported as is by the explain mode.
+type predicate struct {
+	name     string
+	field    string
+	expected string
+	value    func(c Clinic) string
+	test     func(c Clinic) bool
+}
+
+// searchFilter is a validated search ready to be run against the clinics of a snapshot
+type searchFilter struct {
+	params     SearchParams
+	predicates []predicate
+	query      queryNode
+	// names are the lower cased names searched, the name of the params followed by its synonyms
+	names []string
+	// origin is the location of a proximity search, clinics are ordered by their distance to it
+	origin *geo.Point
+}
+
+// newSearchFilter validates the search params and prepares the filter they describe,
+// the names searched are expanded by the synonym dictionary when one is given
+func newSearchFilter(params SearchParams, synonyms *Synonyms) (searchFilter, error) {
+	filter := searchFilter{params: params}
+
+	validate := validatorutil.GetValidator()
+
+	err := validate.Struct(params)
+	if err != nil {
+		return filter, searchError{codeInvalidAttributes, validatorutil.GetTranslatedErrors(err)}
+	}
+
+	window, err := searchWindow(params.From, params.To)
+	if err != nil {
+		return filter, searchError{codeInvalidAttributes, map[string]string{"availability": err.Error()}}
+	}
+
+	switch params.Match {
+	case "", MatchCovers, MatchOverlaps, MatchWithin:
+	default:
+		return filter, searchError{codeInvalidAttributes, map[string]string{
+			"match": fmt.Sprintf("match must be one of %s, %s or %s", MatchCovers, MatchOverlaps, MatchWithin),
+		}}
+	}
+
+	if (params.Lat == nil) != (params.Lon == nil) {
+		return filter, searchError{codeInvalidAttributes, map[string]string{"lat": "lat and lon must be given together"}}
+	}
+
+	if params.RadiusKm > 0 && params.Lat == nil {
+		return filter, searchError{codeInvalidAttributes, map[string]string{"radius_km": "radius_km requires lat and lon"}}
+	}
+
+	if params.Lat != nil {
+		filter.origin = &geo.Point{Lat: *params.Lat, Lon: *params.Lon}
+	}
+
+	if params.Q != "" {
+		filter.query, err = parseQuery(params.Q)
+		if err != nil {
+			return filter, searchError{codeInvalidQuery, map[string]string{"q": err.Error()}}
+		}
+
+		filter.query = filter.query.withSynonyms(synonyms)
+	}
+
+	if params.Name != "" {
+		filter.names = synonyms.expand(params.Name)
+		filter.predicates = append(filter.predicates, nameContains(filter.names))
+	}
+
+	if len(params.State) > 0 {
+		filter.predicates = append(filter.predicates, stateIn(params.State))
+	}
+
+	if len(params.Type) > 0 {
+		filter.predicates = append(filter.predicates, typeIn(params.Type))
+	}
+
+	if params.From != "" || params.To != "" {
+		filter.predicates = append(filter.predicates, availabilityMatches(params.Match, window))
+	}
+
+	if filter.query != nil {
+		filter.predicates = append(filter.predicates, queryMatches(filter.query))
+	}
+
+	if params.RadiusKm > 0 {
+		filter.predicates = append(filter.predicates, withinRadius(*filter.origin, params.RadiusKm))
+	}
+
+	return filter, nil
+}
+
+// writeSearchError renders the error returned by newSearchFilter
+func writeSearchError(w http.ResponseWriter, r *http.Request, err error) {
+	attrErrMessages := validatorutil.GetAttributeErrorMessages()
+
+	if searchErr, ok := err.(searchError); ok {
+		httputil.JSONError(w, r, searchErr.code, searchErr.messages)
+		return
+	}
+
+	httputil.JSONError(w, r, codeInvalidAttributes.WithMessage(err.Error()), attrErrMessages)
+}
+
+// apply returns the clinics matching the search,
+// the clinics of a proximity search are ordered by distance with the ones of unknown location last
+func (f searchFilter) apply(clinics []Clinic) []Clinic {
+	filtered := make([]Clinic, 0, len(clinics))
+	for _, c := range clinics {
+		if f.matches(c) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if f.origin == nil {
+		return filtered
+	}
+
+	for i, c := range filtered {
+		if distance, ok := clinicDistance(c, *f.origin); ok {
+			filtered[i].DistanceKm = &distance
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i].DistanceKm, filtered[j].DistanceKm
+		return a != nil && (b == nil || *a < *b)
+	})
+
+	return filtered
+}
+
+// clinicDistance returns the distance from the origin to the city of the clinic, or the centroid of its state
+// when the city is unknown, rounded to 100 meters
+func clinicDistance(c Clinic, origin geo.Point) (float64, bool) {
+	location, ok := geo.Locate(c.City, c.State)
+	if !ok {
+		return 0, false
+	}
+
+	return math.Round(geo.DistanceKm(origin, location)*10) / 10, true
+}
+
+// matches reports whether the clinic passes every predicate of the search
+func (f searchFilter) matches(c Clinic) bool {
+	for _, p := range f.predicates {
+		if !p.test(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nameContains matches the names containing any of the lower cased names, the searched name and its synonyms
+func nameContains(names []string) predicate {
+	name := "contains"
+	if len(names) > 1 {
+		name = "contains any synonym"
+	}
+
+	return predicate{
+		name:     name,
+		field:    "name",
+		expected: strings.Join(names, ", "),
+		value:    func(c Clinic) string { return strings.ToLower(c.Name) },
+		test: func(c Clinic) bool {
+			value := strings.ToLower(c.Name)
+			for _, n := range names {
+				if strings.Contains(value, n) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// stateCode returns the code of the state given by code or name, e.g. `CA` for `California`,
+// the value itself when it is not a known state
+func stateCode(s string) string {
+	s = strings.TrimSpace(s)
+	if state, ok := geo.LookupState(s); ok {
+		return state.Code
+	}
+
+	return s
+}
+
+// stateIn matches the clinics in any of the states, states being compared by code whether they are given,
+// or stored by the provider, by code or by name
+func stateIn(states []string) predicate {
+	codes := make([]string, len(states))
+	for i, state := range states {
+		codes[i] = stateCode(state)
+	}
+
+	return predicate{
+		name:     "in",
+		field:    "state",
+		expected: strings.Join(codes, ", "),
+		value:    func(c Clinic) string { return stateCode(c.State) },
+		test: func(c Clinic) bool {
+			state := stateCode(c.State)
+			for _, code := range codes {
+				if strings.EqualFold(state, code) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+func typeIn(types []string) predicate {
+	return predicate{
+		name:     "in",
+		field:    "type",
+		expected: strings.Join(types, ", "),
+		value:    func(c Clinic) string { return c.Type },
+		test: func(c Clinic) bool {
+			for _, t := range types {
+				if c.Type == t {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+func availabilityMatches(mode string, window timeWindow) predicate {
+	if mode == "" {
+		mode = MatchCovers
+	}
+
+	return predicate{
+		name:     mode,
+		field:    "availability",
+		expected: window.String(),
+		value: func(c Clinic) string {
+			hours, err := openingHours(c.Availability)
+			if err != nil {
+				return fmt.Sprintf("unreadable %s-%s", c.Availability.From, c.Availability.To)
+			}
+
+			return hours.String()
+		},
+		test: func(c Clinic) bool {
+			return matchAvailability(mode, window, c.Availability)
+		},
+	}
+}
+
+func queryMatches(query queryNode) predicate {
+	return predicate{
+		name:     "matches",
+		field:    "q",
+		expected: query.String(),
+		value:    func(c Clinic) string { return "" },
+		test:     query.eval,
+	}
+}
+
+func withinRadius(origin geo.Point, radiusKm float64) predicate {
+	return predicate{
+		name:     "within radius",
+		field:    "location",
+		expected: fmt.Sprintf("%g km", radiusKm),
+		value: func(c Clinic) string {
+			distance, ok := clinicDistance(c, origin)
+			if !ok {
+				return "unknown"
+			}
+
+			return fmt.Sprintf("%g km", distance)
+		},
+		test: func(c Clinic) bool {
+			distance, ok := clinicDistance(c, origin)
+			return ok && distance <= radiusKm
+		},
+	}
+}
+
+// searchParamsFromQuery reads the search params from the query string of a GET request,
+// the list params are given by repeating them, e.g. `state=CA&state=NV`
+func searchParamsFromQuery(values url.Values) (SearchParams, error) {
+	params := SearchParams{
+		Name:  values.Get("name"),
+		State: values["state"],
+		Type:  values["type"],
+		From:  values.Get("from"),
+		To:    values.Get("to"),
+		Match: values.Get("match"),
+		Q:     values.Get("q"),
+	}
+
+	messages := validatorutil.GetAttributeErrorMessages()
+
+	var err error
+	if params.Lat, err = floatParam(values, "lat"); err != nil {
+		messages["lat"] = err.Error()
+	}
+
+	if params.Lon, err = floatParam(values, "lon"); err != nil {
+		messages["lon"] = err.Error()
+	}
+
+	radius, err := floatParam(values, "radius_km")
+	if err != nil {
+		messages["radius_km"] = err.Error()
+	} else if radius != nil {
+		params.RadiusKm = *radius
+	}
+
+	if len(messages) > 0 {
+		return params, searchError{codeInvalidAttributes, messages}
+	}
+
+	return params, nil
+}
+
+// floatParam returns the number of an optional query parameter, nil when it is missing
+func floatParam(values url.Values, name string) (*float64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+
+	return &v, nil
+}
@@ -1,75 +1,205 @@
 package clinic
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/scratchpay_ademola/internal/requestid"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 )
 
+// the providers the clinics are fetched from, as tagged in the provider views
+const (
+	providerDental = "dental"
+	providerVet    = "vet"
+)
+
+var (
+	providerTag       = tag.MustNewKey("provider")
+	providerStatusTag = tag.MustNewKey("http_status")
+
+	providerRequests = stats.Int64(
+		"clinic/provider/request_count",
+		"Number of fetches of the clinics of a provider",
+		stats.UnitDimensionless,
+	)
+	providerLatency = stats.Float64(
+		"clinic/provider/latency",
+		"Time taken to fetch the clinics of a provider",
+		stats.UnitMilliseconds,
+	)
+	providerResponseBytes = stats.Int64(
+		"clinic/provider/response_bytes",
+		"Size of the clinics document of a provider",
+		stats.UnitBytes,
+	)
+
+	// providerViews are the client side views of the fetches, tagged by provider and by HTTP status,
+	// `error` when no response was received
+	providerViews = []*view.View{
+		{
+			Name:        "clinic/provider/request_count",
+			Description: "Number of fetches of the clinics by provider and status",
+			TagKeys:     []tag.Key{providerTag, providerStatusTag},
+			Measure:     providerRequests,
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "clinic/provider/latency",
+			Description: "Distribution of the time taken to fetch the clinics by provider and status",
+			TagKeys:     []tag.Key{providerTag, providerStatusTag},
+			Measure:     providerLatency,
+			Aggregation: httputil.LatencyDistribution,
+		},
+		{
+			Name:        "clinic/provider/response_bytes",
+			Description: "Distribution of the size of the clinics documents by provider and status",
+			TagKeys:     []tag.Key{providerTag, providerStatusTag},
+			Measure:     providerResponseBytes,
+			Aggregation: httputil.SizeDistribution,
+		},
+	}
+)
+
 type DataDownloader struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	client    *http.Client
+	dentalURL string
+	vetURL    string
 }
 
-func NewDataDownloader(l *zap.Logger) *DataDownloader {
+// NewDataDownloader creates a DataDownloader fetching the clinics of the providers,
+// each fetch failing when the provider has not responded within the timeout
+func NewDataDownloader(l *zap.Logger, timeout time.Duration) *DataDownloader {
+	view.Register(providerViews...)
+
 	return &DataDownloader{
-		logger: l,
+		logger:    l,
+		client:    &http.Client{Timeout: timeout},
+		dentalURL: dentalClinicsURL,
+		vetURL:    vetClinicsURL,
 	}
 }
 
-func (d *DataDownloader) GetClinicData(logger *zap.Logger) ([]Clinic, error) {
-	var clinics []Clinic
+// GetClinicData fetches the clinics of both providers concurrently, the dental clinics followed by the vet clinics
+// so that unchanged data is returned in the same order. It fails when either provider fails, rather than
+// returning the clinics of a single provider.
+func (d *DataDownloader) GetClinicData(ctx context.Context, logger *zap.Logger) ([]Clinic, error) {
+	var dentalClinics, vetClinics []Clinic
+	var dentalErr, vetErr error
 
 	var sg sync.WaitGroup
 	sg.Add(2)
 
 	go func() {
-		dentalClinics, err := getDentalClinics()
-		if err != nil {
-			logger.Error("error fetching dental clinics", zap.Error(err))
-		}
-		clinics = append(clinics, dentalClinics...)
+		dentalClinics, dentalErr = d.getDentalClinics(ctx)
 		sg.Done()
 	}()
 
 	go func() {
-		vetClinics, err := getVetClinics()
-		if err != nil {
-			logger.Error("error fetching vet clinics", zap.Error(err))
-		}
-		clinics = append(clinics, vetClinics...)
+		vetClinics, vetErr = d.getVetClinics(ctx)
 		sg.Done()
 	}()
 
 	sg.Wait()
+
+	if dentalErr != nil {
+		logger.Error("error fetching dental clinics", zap.Error(dentalErr))
+		return nil, fmt.Errorf("fetching dental clinics: %w", dentalErr)
+	}
+
+	if vetErr != nil {
+		logger.Error("error fetching vet clinics", zap.Error(vetErr))
+		return nil, fmt.Errorf("fetching vet clinics: %w", vetErr)
+	}
+
+	clinics := make([]Clinic, 0, len(dentalClinics)+len(vetClinics))
+	clinics = append(clinics, dentalClinics...)
+	clinics = append(clinics, vetClinics...)
+
 	return clinics, nil
 }
 
-func fetchData(url string) ([]byte, error) {
+// fetchData downloads the document of the provider at url within a `clinic/provider/fetch` span,
+// forwarding the request ID and the trace context of the context.
+// The fetch is not cancelled along with the context, its result being shared by every request waiting on the snapshot,
+// it is bounded by the timeout of the client instead.
+func (d *DataDownloader) fetchData(ctx context.Context, provider, url string) ([]byte, error) {
+	ctx, span := trace.StartSpan(ctx, "clinic/provider/fetch", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	span.AddAttributes(
+		trace.StringAttribute("provider", provider),
+		trace.StringAttribute(ochttp.URLAttribute, url),
+	)
+
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Close = true
 
-	resp, err := http.DefaultClient.Do(req)
+	if id := requestid.From(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	httputil.TraceFormat.SpanContextToRequest(span.SpanContext(), req)
+
+	start := time.Now()
+	status := "error"
+	var body []byte
+
+	defer func() {
+		stats.RecordWithTags(
+			context.Background(),
+			[]tag.Mutator{
+				tag.Upsert(providerTag, provider),
+				tag.Upsert(providerStatusTag, status),
+			},
+			providerRequests.M(1),
+			providerLatency.M(httputil.Milliseconds(time.Since(start))),
+			providerResponseBytes.M(int64(len(body))),
+		)
+	}()
+
+	resp, err := d.client.Do(req)
 	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	status = strconv.Itoa(resp.StatusCode)
+
+	span.AddAttributes(trace.Int64Attribute(ochttp.StatusCodeAttribute, int64(resp.StatusCode)))
+	span.SetStatus(ochttp.TraceStatus(resp.StatusCode, resp.Status))
+
+	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s provider responded with status %d", provider, resp.StatusCode)
+	}
+
 	return body, nil
 }
 
-func getDentalClinics() ([]Clinic, error) {
-	body, err := fetchData(dentalClinicsURL)
+func (d *DataDownloader) getDentalClinics(ctx context.Context) ([]Clinic, error) {
+	body, err := d.fetchData(ctx, providerDental, d.dentalURL)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +219,8 @@ func parseDentalClinics(dentalClinics []DentalClinic) []Clinic {
 		clinics = append(clinics, Clinic{
 			Name:         cl.Name,
 			State:        cl.State,
+			City:         cl.City,
+			Type:         TypeDental,
 			Availability: cl.Availability,
 		})
 	}
@@ -96,9 +228,9 @@ func parseDentalClinics(dentalClinics []DentalClinic) []Clinic {
 	return clinics
 }
 
-func getVetClinics() ([]Clinic, error) {
+func (d *DataDownloader) getVetClinics(ctx context.Context) ([]Clinic, error) {
 
-	body, err := fetchData(vetClinicsURL)
+	body, err := d.fetchData(ctx, providerVet, d.vetURL)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +250,8 @@ func parseVetClinics(vetClinics []VetClinic) []Clinic {
 		clinics = append(clinics, Clinic{
 			Name:         cl.Name,
 			State:        cl.State,
+			City:         cl.City,
+			Type:         TypeVet,
 			Availability: cl.Availability,
 		})
 	}
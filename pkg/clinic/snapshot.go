@@ -0,0 +1,189 @@
+package clinic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+)
+
+// Snapshot is an immutable version of the clinic data fetched from the providers
+type Snapshot struct {
+	Version   uint64
+	FetchedAt time.Time
+	Clinics   []Clinic
+
+	digest [sha256.Size]byte
+}
+
+// SnapshotStore serves the clinic data from versioned snapshots.
+//
+// The current snapshot is fetched again through the DataFetcher once it is older than the ttl,
+// a new version is only created when the fetched data differs from the current one.
+// The most recent `retain` versions are kept so that paginated reads can carry on against the version they started on.
+type SnapshotStore struct {
+	fetcher DataFetcher
+	ttl     time.Duration
+	retain  int
+
+	// refresh is held by the caller refreshing the snapshot
+	refresh chan struct{}
+
+	mu        sync.RWMutex
+	snapshots []*Snapshot // ordered from oldest to most recent
+	checkedAt time.Time
+	version   uint64
+}
+
+// NewSnapshotStore creates a SnapshotStore fetching data through the fetcher
+func NewSnapshotStore(fetcher DataFetcher, ttl time.Duration, retain int) *SnapshotStore {
+	if retain < 1 {
+		retain = 1
+	}
+
+	return &SnapshotStore{
+		fetcher: fetcher,
+		ttl:     ttl,
+		retain:  retain,
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// Current returns the most recent snapshot, fetching the data again when the snapshot is missing or stale.
+//
+// Only one caller refreshes the data at a time, the others are served the stale snapshot meanwhile,
+// or wait for the refresh when there is none yet. If fetching fails while a snapshot is already available,
+// the stale snapshot is returned. The lookup is traced in a `clinic/snapshot` span, the context is handed to the DataFetcher of the refresh, if one is needed.
+func (s *SnapshotStore) Current(ctx context.Context, l *zap.Logger) (*Snapshot, error) {
+	ctx, span := trace.StartSpan(ctx, "clinic/snapshot")
+	defer span.End()
+
+	if snapshot := s.fresh(); snapshot != nil {
+		span.AddAttributes(trace.Int64Attribute("snapshot_version", int64(snapshot.Version)))
+		return snapshot, nil
+	}
+
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+		if snapshot := s.latest(); snapshot != nil {
+			span.Annotate(nil, "serving stale data while the clinic data is refreshed")
+			span.AddAttributes(trace.Int64Attribute("snapshot_version", int64(snapshot.Version)))
+			return snapshot, nil
+		}
+
+		select {
+		case s.refresh <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	defer func() { <-s.refresh }()
+
+	if snapshot := s.fresh(); snapshot != nil {
+		span.AddAttributes(trace.Int64Attribute("snapshot_version", int64(snapshot.Version)))
+		return snapshot, nil
+	}
+
+	span.Annotate(nil, "refreshing the clinic data")
+
+	clinics, err := s.fetcher.GetClinicData(ctx, l)
+	if err != nil {
+		if snapshot := s.latest(); snapshot != nil {
+			l.Warn("failed refreshing clinic snapshot, serving stale data",
+				zap.Uint64("version", snapshot.Version), zap.Error(err))
+			span.Annotate([]trace.Attribute{trace.StringAttribute("error", err.Error())}, "serving stale data")
+			span.AddAttributes(trace.Int64Attribute("snapshot_version", int64(snapshot.Version)))
+			return snapshot, nil
+		}
+
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+		return nil, err
+	}
+
+	snapshot := s.store(clinics)
+	span.AddAttributes(trace.Int64Attribute("snapshot_version", int64(snapshot.Version)))
+
+	return snapshot, nil
+}
+
+// Version returns the retained snapshot with the given version
+func (s *SnapshotStore) Version(version uint64) (*Snapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, snapshot := range s.snapshots {
+		if snapshot.Version == version {
+			return snapshot, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *SnapshotStore) fresh() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.snapshots) == 0 || time.Since(s.checkedAt) >= s.ttl {
+		return nil
+	}
+
+	return s.snapshots[len(s.snapshots)-1]
+}
+
+func (s *SnapshotStore) latest() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.snapshots) == 0 {
+		return nil
+	}
+
+	return s.snapshots[len(s.snapshots)-1]
+}
+
+func (s *SnapshotStore) store(clinics []Clinic) *Snapshot {
+	now := time.Now()
+	digest := digestClinics(clinics)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkedAt = now
+
+	if n := len(s.snapshots); n > 0 && s.snapshots[n-1].digest == digest {
+		return s.snapshots[n-1]
+	}
+
+	s.version++
+	snapshot := &Snapshot{
+		Version:   s.version,
+		FetchedAt: now,
+		Clinics:   clinics,
+		digest:    digest,
+	}
+
+	s.snapshots = append(s.snapshots, snapshot)
+	if len(s.snapshots) > s.retain {
+		s.snapshots = append([]*Snapshot(nil), s.snapshots[len(s.snapshots)-s.retain:]...)
+	}
+
+	return snapshot
+}
+
+// dataID identifies the data of the snapshot, unlike its version which is only unique within the process
+func (s *Snapshot) dataID() string {
+	return hex.EncodeToString(s.digest[:8])
+}
+
+func digestClinics(clinics []Clinic) [sha256.Size]byte {
+	data, _ := json.Marshal(clinics)
+
+	return sha256.Sum256(data)
+}
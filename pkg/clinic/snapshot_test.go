@@ -0,0 +1,190 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestSnapshotStoreServesStaleData(t *testing.T) {
+	clinics := []Clinic{{Name: "Good Health Home", State: "FL"}}
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Once()
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(nil, errors.New("provider unavailable")).Once()
+
+	store := NewSnapshotStore(fetcherMock, 0, 2)
+
+	first, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+
+	stale, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+	assert.Same(t, first, stale)
+	assert.True(t, fetcherMock.AssertExpectations(t))
+}
+
+func TestSnapshotStoreServesStaleDataWhileRefreshing(t *testing.T) {
+	clinics := []Clinic{{Name: "Good Health Home", State: "FL"}}
+	refreshing, release := make(chan struct{}), make(chan struct{})
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Once()
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Run(func(m.Arguments) {
+		close(refreshing)
+		<-release
+	}).Return(clinics, nil).Once()
+
+	store := NewSnapshotStore(fetcherMock, 0, 2)
+
+	first, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.Current(context.Background(), zap.NewNop())
+	}()
+
+	<-refreshing
+
+	// the refresh hangs, the other callers get the stale snapshot rather than waiting for it
+	stale, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+	assert.Same(t, first, stale)
+
+	close(release)
+	<-done
+	assert.True(t, fetcherMock.AssertExpectations(t))
+}
+
+func TestSnapshotStoreKeepsVersionOfUnchangedData(t *testing.T) {
+	clinics := []Clinic{{Name: "Good Health Home", State: "FL"}, {Name: "German Pets Clinics", State: "KS"}}
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(append([]Clinic(nil), clinics...), nil).Twice()
+
+	store := NewSnapshotStore(fetcherMock, 0, 2)
+
+	first, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+
+	second, err := store.Current(context.Background(), zap.NewNop())
+	assert.NoError(t, err)
+	assert.Equal(t, first.Version, second.Version)
+}
+
+func TestGetClinicData(t *testing.T) {
+	dental := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "Good Health Home", "stateName": "Alaska", "availability": {"from": "10:00", "to": "19:30"}}]`))
+	}))
+	defer dental.Close()
+
+	vet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"clinicName": "National Veterinary Clinic", "stateCode": "CA", "opening": {"from": "15:00", "to": "22:30"}}]`))
+	}))
+	defer vet.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	downloader := NewDataDownloader(zap.NewNop(), time.Second)
+
+	// the dental clinics always come first, whichever provider responds first
+	for i := 0; i < 5; i++ {
+		downloader.dentalURL, downloader.vetURL = dental.URL, vet.URL
+
+		clinics, err := downloader.GetClinicData(context.Background(), zap.NewNop())
+		assert.NoError(t, err)
+		if assert.Len(t, clinics, 2) {
+			assert.Equal(t, "Good Health Home", clinics[0].Name)
+			assert.Equal(t, "National Veterinary Clinic", clinics[1].Name)
+		}
+	}
+
+	downloader.vetURL = failing.URL
+
+	clinics, err := downloader.GetClinicData(context.Background(), zap.NewNop())
+	assert.Error(t, err)
+	assert.Nil(t, clinics)
+
+	downloader.dentalURL, downloader.vetURL = failing.URL, vet.URL
+
+	_, err = downloader.GetClinicData(context.Background(), zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestGetClinicDataTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	downloader := NewDataDownloader(zap.NewNop(), 50*time.Millisecond)
+	downloader.dentalURL, downloader.vetURL = hung.URL, hung.URL
+
+	start := time.Now()
+	_, err := downloader.GetClinicData(context.Background(), zap.NewNop())
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second, "fetched for %s", time.Since(start))
+}
+
+func TestCursorFromAnotherInstance(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL"},
+		{Name: "National Veterinary Clinic", State: "CA"},
+		{Name: "German Pets Clinics", State: "KS"},
+	}
+
+	router := func(clinics []Clinic) *chi.Mux {
+		fetcherMock := &DataFetcherMock{}
+		fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil)
+
+		r := chi.NewRouter()
+		r.Get("/v1/clinics/", GetAllClinics(NewSnapshotStore(fetcherMock, 0, 1), testCursors))
+
+		return r
+	}
+
+	issuer := router(clinics)
+
+	response := httptest.NewRecorder()
+	issuer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/?size=2", nil))
+	var page struct {
+		Meta httputil.PageMeta
+	}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&page))
+	assert.NotEmpty(t, page.Meta.NextCursor)
+
+	tests := []struct {
+		name     string
+		clinics  []Clinic
+		wantCode int
+	}{
+		{name: "accepted with the same data", clinics: clinics, wantCode: http.StatusOK},
+		{name: "expired with different data at the same version", clinics: clinics[1:], wantCode: http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			router(tt.clinics).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "http://www.test.com/v1/clinics/?size=2&cursor="+page.Meta.NextCursor, nil))
+
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package clinic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/httputil"
+)
+
+// sortKeys maps the fields clinics can be sorted by to the value compared for each clinic
+var sortKeys = map[string]func(c Clinic) string{
+	"name":  func(c Clinic) string { return strings.ToLower(c.Name) },
+	"state": func(c Clinic) string { return strings.ToLower(c.State) },
+	"type":  func(c Clinic) string { return c.Type },
+	"from":  func(c Clinic) string { return c.Availability.From },
+	"to":    func(c Clinic) string { return c.Availability.To },
+}
+
+func validateSort(fields []httputil.SortField) error {
+	for _, field := range fields {
+		if _, ok := sortKeys[field.Name]; !ok {
+			return fmt.Errorf("sort field %q is not supported", field.Name)
+		}
+	}
+
+	return nil
+}
+
+// sortClinics orders clinics in place by the given fields, clinics comparing equal keep their original order
+func sortClinics(clinics []Clinic, fields []httputil.SortField) {
+	if len(fields) == 0 {
+		return
+	}
+
+	sort.SliceStable(clinics, func(i, j int) bool {
+		return compareClinics(clinics[i], clinics[j], fields) < 0
+	})
+}
+
+func compareClinics(a, b Clinic, fields []httputil.SortField) int {
+	for _, field := range fields {
+		key := sortKeys[field.Name]
+
+		c := strings.Compare(key(a), key(b))
+		if field.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
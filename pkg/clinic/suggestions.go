@@ -0,0 +1,58 @@
+package clinic
+
+import (
+	"strings"
+
+	"github.com/scratchpay_ademola/internal/spelling"
+)
+
+// suggestions returns the spelling suggestions for the name of a search that matched no clinic,
+// drawn from the names of the clinics of the snapshot. States are not suggested as the searched states
+// are known ones, a misspelled state being rejected by the validator along with the closest state.
+func (f searchFilter) suggestions(snapshot *Snapshot) map[string][]string {
+	suggestions := make(map[string][]string)
+
+	if f.params.Name != "" {
+		if name, ok := suggestName(f.params.Name, snapshot.Clinics); ok {
+			suggestions["name"] = []string{name}
+		}
+	}
+
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	return suggestions
+}
+
+// suggestName corrects each word of the name missing from the clinic names to the closest word they hold,
+// reporting whether any word was corrected
+func suggestName(name string, clinics []Clinic) (string, bool) {
+	// the vocabulary keeps the first spelling of each word
+	known := make(map[string]bool)
+	var vocabulary []string
+	for _, c := range clinics {
+		for _, word := range strings.Fields(c.Name) {
+			if lower := strings.ToLower(word); !known[lower] {
+				known[lower] = true
+				vocabulary = append(vocabulary, word)
+			}
+		}
+	}
+
+	words := strings.Fields(name)
+
+	corrected := false
+	for i, word := range words {
+		if known[strings.ToLower(word)] {
+			continue
+		}
+
+		if suggestion := spelling.Suggest(word, vocabulary, 1); len(suggestion) > 0 {
+			words[i] = suggestion[0]
+			corrected = true
+		}
+	}
+
+	return strings.Join(words, " "), corrected
+}
@@ -0,0 +1,107 @@
+package clinic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+func TestSearchSuggestions(t *testing.T) {
+	clinics := []Clinic{
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "15:00", To: "20:00"}},
+		{Name: "National Veterinary Clinic", State: "California", Type: TypeVet, Availability: Availability{From: "15:00", To: "22:30"}},
+		{Name: "Desert Dental", State: "NV", Type: TypeDental, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Bluegrass Vets", State: "KY", Type: TypeVet, Availability: Availability{From: "09:00", To: "17:00"}},
+		{Name: "Ozark Animal Care", State: "Arkansas", Type: TypeVet, Availability: Availability{From: "09:00", To: "17:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "misspelled name",
+			body:     `{"name": "natonal vetrinary"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0},\"suggestions\":{\"name\":[\"National Veterinary\"]}}\n",
+		},
+		{
+			name:     "transposed letters",
+			body:     `{"name": "Good Heatlh"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0},\"suggestions\":{\"name\":[\"Good Health\"]}}\n",
+		},
+		{
+			name:     "state named differently by the clinics",
+			body:     `{"state": ["Nevada", "TX"]}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Desert Dental\",\"state\":\"NV\",\"type\":\"dental\",\"availability\":{\"from\":\"08:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name:     "no suggestion for a state named differently by the clinics",
+			body:     `{"state": "CA", "type": "dental"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
+		},
+		{
+			name:     "no suggestion for a state code without clinics",
+			body:     `{"state": "KS"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
+		},
+		{
+			name:     "no suggestion for a state name without clinics",
+			body:     `{"state": ["Kansas", "Texas"]}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
+		},
+		{
+			name:     "name suggested along a state without clinics",
+			body:     `{"name": "Bluegras", "state": "Kansas"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0},\"suggestions\":{\"name\":[\"Bluegrass\"]}}\n",
+		},
+		{
+			name:     "no suggestion for known words",
+			body:     `{"name": "Desert", "type": "vet"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[],\"meta\":{\"total\":0,\"page\":1,\"size\":50,\"total_pages\":0}}\n",
+		},
+		{
+			name:     "no suggestion when clinics are found",
+			body:     `{"name": "Desert"}`,
+			wantCode: http.StatusOK,
+			wantBody: "{\"data\":[{\"name\":\"Desert Dental\",\"state\":\"NV\",\"type\":\"dental\",\"availability\":{\"from\":\"08:00\",\"to\":\"20:00\"}}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1}}\n",
+		},
+		{
+			name:     "misspelled state is rejected with a suggestion",
+			body:     `{"state": "Calfornia"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid attributes\",\"code\":\"SEARCH_INVALID_ATTRIBUTES\",\"messages\":{\"state[0]\":\"state[0] must be a known state code or name, did you mean California?\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package clinic
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// The synonym dictionary is a text file with one rule per line, blank lines and lines starting with `#` are ignored:
+//
+//   vet clinic, animal hospital, veterinary   phrases separated by commas are equivalent
+//   dr. => doctor                             a search for the phrases on the left also looks for the ones on the right
+//
+// Phrases are matched ignoring case as whole words of a searched name, a search for `dr. smith` also looks for `doctor smith`.
+
+// maxSynonymVariants bounds the number of names a search term expands to
+const maxSynonymVariants = 32
+
+// Synonyms is an immutable synonym dictionary
+type Synonyms struct {
+	rules []synonymRule
+}
+
+// synonymRule expands the phrases of from to the phrases of to
+type synonymRule struct {
+	from []string
+	to   []string
+}
+
+// ParseSynonyms reads a synonym dictionary
+func ParseSynonyms(r io.Reader) (*Synonyms, error) {
+	s := &Synonyms{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var rule synonymRule
+
+		if parts := strings.Split(text, "=>"); len(parts) == 2 {
+			rule.from, rule.to = synonymPhrases(parts[0]), synonymPhrases(parts[1])
+			if len(rule.from) == 0 || len(rule.to) == 0 {
+				return nil, fmt.Errorf("line %d: an alias needs phrases on both sides of =>", line)
+			}
+		} else if len(parts) == 1 {
+			rule.from = synonymPhrases(text)
+			rule.to = rule.from
+			if len(rule.from) < 2 {
+				return nil, fmt.Errorf("line %d: a synonym group needs at least two phrases", line)
+			}
+		} else {
+			return nil, fmt.Errorf("line %d: an alias holds a single =>", line)
+		}
+
+		s.rules = append(s.rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func synonymPhrases(list string) []string {
+	var phrases []string
+	for _, phrase := range strings.Split(list, ",") {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	return phrases
+}
+
+// expand returns the lower cased term followed by the variants the dictionary adds to it
+func (s *Synonyms) expand(term string) []string {
+	variants := []string{strings.ToLower(term)}
+	if s == nil {
+		return variants
+	}
+
+	seen := map[string]bool{variants[0]: true}
+
+	for _, rule := range s.rules {
+		for _, variant := range variants {
+			for _, from := range rule.from {
+				for _, to := range rule.to {
+					expanded, ok := replacePhrase(variant, from, to)
+					if ok && !seen[expanded] && len(variants) < maxSynonymVariants {
+						seen[expanded] = true
+						variants = append(variants, expanded)
+					}
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// replacePhrase replaces the occurrences of the phrase standing as whole words in s,
+// so that `vet` is replaced in `vet clinic` but not in `veterinary`
+func replacePhrase(s, phrase, replacement string) (string, bool) {
+	var b strings.Builder
+
+	replaced := false
+	for {
+		i := strings.Index(s, phrase)
+		if i < 0 {
+			break
+		}
+
+		end := i + len(phrase)
+		if isWordBoundary(s, i-1) && isWordBoundary(s, end) {
+			b.WriteString(s[:i])
+			b.WriteString(replacement)
+			replaced = true
+		} else {
+			b.WriteString(s[:end])
+		}
+
+		s = s[end:]
+	}
+
+	b.WriteString(s)
+
+	return b.String(), replaced
+}
+
+// isWordBoundary reports whether the byte at i is outside of s or not part of a word
+func isWordBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+
+	c := s[i]
+
+	return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= utf8.RuneSelf)
+}
+
+// SynonymStore holds the synonym dictionary loaded from a file, reloading it when the file changes
+type SynonymStore struct {
+	path string
+
+	mu       sync.RWMutex
+	synonyms *Synonyms
+	modTime  time.Time
+}
+
+// NewSynonymStore creates a SynonymStore loading the dictionary from path, an empty path disables synonyms
+func NewSynonymStore(path string) (*SynonymStore, error) {
+	s := &SynonymStore{path: path}
+
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Current returns the dictionary currently loaded
+func (s *SynonymStore) Current() *Synonyms {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.synonyms
+}
+
+// Reload loads the dictionary again when the file was modified since it was last loaded, reporting whether it did.
+// An invalid dictionary is rejected and the current one is kept.
+func (s *SynonymStore) Reload() (bool, error) {
+	if s.path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	synonyms, err := ParseSynonyms(f)
+	if err != nil {
+		return false, fmt.Errorf("error parsing synonyms %s: %s", s.path, err)
+	}
+
+	s.mu.Lock()
+	s.synonyms = synonyms
+	s.modTime = info.ModTime()
+	s.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch checks the file for changes every interval until the context is done
+func (s *SynonymStore) Watch(ctx context.Context, interval time.Duration, l *zap.Logger) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				l.Error("failed reloading synonyms, keeping the current ones", zap.String("path", s.path), zap.Error(err))
+				continue
+			}
+
+			if reloaded {
+				l.Info("reloaded synonyms", zap.String("path", s.path))
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package clinic
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+const testDictionary = `
+# clinic synonyms
+vet clinic, Animal Hospital
+vet, veterinary
+dr. => doctor
+`
+
+func TestParseSynonyms(t *testing.T) {
+	tests := []struct {
+		name       string
+		dictionary string
+		wantErr    string
+	}{
+		{name: "valid dictionary", dictionary: testDictionary},
+		{name: "group of a single phrase", dictionary: "vet clinic\nvet, veterinary", wantErr: "line 1: a synonym group needs at least two phrases"},
+		{name: "alias missing a side", dictionary: "\ndr. =>", wantErr: "line 2: an alias needs phrases on both sides of =>"},
+		{name: "chained aliases", dictionary: "dr. => doctor => physician", wantErr: "line 1: an alias holds a single =>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSynonyms(strings.NewReader(tt.dictionary))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSynonymsExpand(t *testing.T) {
+	synonyms, err := ParseSynonyms(strings.NewReader(testDictionary))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{term: "Animal Hospital", want: []string{"animal hospital", "vet clinic", "veterinary clinic"}},
+		{term: "dr. Smith", want: []string{"dr. smith", "doctor smith"}},
+		{term: "doctor smith", want: []string{"doctor smith"}},
+		{term: "veterinary", want: []string{"veterinary", "vet"}},
+		{term: "corvette", want: []string{"corvette"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			assert.Equal(t, tt.want, synonyms.expand(tt.term))
+		})
+	}
+
+	var none *Synonyms
+	assert.Equal(t, []string{"animal hospital"}, none.expand("Animal Hospital"))
+}
+
+func TestSynonymStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "synonyms")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "synonyms.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("dr. => doctor"), 0600))
+
+	store, err := NewSynonymStore(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dr. who", "doctor who"}, store.Current().expand("dr. who"))
+
+	reloaded, err := store.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// an invalid dictionary keeps the current one
+	assert.NoError(t, ioutil.WriteFile(path, []byte("dr."), 0600))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	_, err = store.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, []string{"dr. who", "doctor who"}, store.Current().expand("dr. who"))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("dr., doc"), 0600))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+	reloaded, err = store.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, []string{"dr. who", "doc who"}, store.Current().expand("dr. who"))
+}
+
+func TestSearchSynonyms(t *testing.T) {
+	synonyms, err := ParseSynonyms(strings.NewReader(testDictionary))
+	assert.NoError(t, err)
+
+	clinics := []Clinic{
+		{Name: "City Vet Clinic", State: "FL", Type: TypeVet, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Doctor Smile", State: "CA", Type: TypeDental, Availability: Availability{From: "08:00", To: "20:00"}},
+		{Name: "Good Health Home", State: "FL", Type: TypeDental, Availability: Availability{From: "08:00", To: "20:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantBody string
+	}{
+		{
+			name:  "expands the searched name",
+			query: "?explain=true&fields=name",
+			body:  `{"name": "animal hospital"}`,
+			wantBody: "{\"data\":[{\"name\":\"City Vet Clinic\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"explain\":[" +
+				"{\"clinic\":\"City Vet Clinic\",\"matched\":true,\"predicates\":[" +
+				"{\"predicate\":\"contains any synonym\",\"field\":\"name\",\"value\":\"city vet clinic\",\"expected\":\"animal hospital, vet clinic, veterinary clinic\",\"passed\":true}]}]}\n",
+		},
+		{
+			name:  "expands the name terms of a query",
+			query: "?explain=true&fields=name",
+			body:  `{"q": "name:\"dr.\" OR name:dentist"}`,
+			wantBody: "{\"data\":[{\"name\":\"Doctor Smile\"}],\"meta\":{\"total\":1,\"page\":1,\"size\":50,\"total_pages\":1},\"explain\":[" +
+				"{\"clinic\":\"Doctor Smile\",\"matched\":true,\"predicates\":[" +
+				"{\"predicate\":\"matches\",\"field\":\"q\",\"value\":\"\",\"expected\":\"(name:\\\"dr.\\\"|\\\"doctor\\\" OR name:\\\"dentist\\\")\",\"passed\":true}," +
+				"{\"predicate\":\"q term\",\"field\":\"name\",\"value\":\"doctor smile\",\"expected\":\"dr., doctor\",\"passed\":true}," +
+				"{\"predicate\":\"q term\",\"field\":\"name\",\"value\":\"doctor smile\",\"expected\":\"dentist\",\"passed\":false}]}]}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcherMock := &DataFetcherMock{}
+			fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return(clinics, nil).Maybe()
+
+			request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search"+tt.query, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), &SynonymStore{synonyms: synonyms}, testCursors))
+			r.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, http.StatusOK, response.Code)
+		})
+	}
+}
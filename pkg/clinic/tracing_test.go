@@ -0,0 +1,156 @@
+package clinic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/scratchpay_ademola/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+)
+
+// spanRecorder keeps the spans ended while it is registered
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (s *spanRecorder) ExportSpan(sd *trace.SpanData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.spans = append(s.spans, sd)
+}
+
+// span returns the last span ended with the given name, nil when there is none
+func (s *spanRecorder) span(name string) *trace.SpanData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.spans) - 1; i >= 0; i-- {
+		if s.spans[i].Name == name {
+			return s.spans[i]
+		}
+	}
+
+	return nil
+}
+
+func recordSpans(t *testing.T) *spanRecorder {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+
+	t.Cleanup(func() {
+		trace.UnregisterExporter(recorder)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+	})
+
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	fetcherMock := &DataFetcherMock{}
+	fetcherMock.On("GetClinicData", m.Anything, m.Anything).Return([]Clinic{{Name: "Good Health Home", State: "FL"}}, nil)
+
+	routes := chi.NewRouter()
+	routes.Post("/v1/clinics/search", Search(NewSnapshotStore(fetcherMock, 0, 1), NewResultCache(10, 100), testSynonyms, testCursors))
+
+	handler := httputil.RequestID(httputil.Tracing(routes))
+
+	request := httptest.NewRequest(http.MethodPost, "http://www.test.com/v1/clinics/search", strings.NewReader(`{"state": "FL"}`))
+	request.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	request.Header.Set("X-Request-ID", "trace-test")
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	server := recorder.span("/v1/clinics/search")
+	if assert.NotNil(t, server) {
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", server.TraceID.String())
+		assert.Equal(t, "b7ad6b7169203331", server.ParentSpanID.String())
+		assert.True(t, server.HasRemoteParent)
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+		assert.Equal(t, "POST", server.Attributes["http.method"])
+		assert.Equal(t, int64(http.StatusOK), server.Attributes["http.status_code"])
+		assert.Equal(t, "trace-test", server.Attributes["request_id"])
+	}
+
+	for _, name := range []string{"clinic/snapshot", "clinic/search"} {
+		span := recorder.span(name)
+		if assert.NotNil(t, span, name) && server != nil {
+			assert.Equal(t, server.TraceID, span.TraceID, name)
+			assert.Equal(t, server.SpanID, span.ParentSpanID, name)
+		}
+	}
+
+	search := recorder.span("clinic/search")
+	if assert.NotNil(t, search) {
+		assert.Equal(t, int64(1), search.Attributes["results"])
+		assert.Equal(t, false, search.Attributes["cache_hit"])
+	}
+}
+
+func TestTracingUnmatchedRoute(t *testing.T) {
+	routes := chi.NewRouter()
+	routes.Route("/v1/clinics", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	handler := httputil.Tracing(routes)
+
+	for _, url := range []string{"/v1/unknown/42", "/v1/clinics/unknown", "/v1/clinics/foo/bar"} {
+		t.Run(url, func(t *testing.T) {
+			recorder := recordSpans(t)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://www.test.com"+url, nil))
+
+			span := recorder.span("unmatched")
+			if assert.NotNil(t, span) {
+				assert.False(t, span.HasRemoteParent)
+				assert.Equal(t, "unmatched", span.Attributes["http.route"])
+				assert.Equal(t, int64(http.StatusNotFound), span.Attributes["http.status_code"])
+				assert.Equal(t, int32(trace.StatusCodeNotFound), span.Status.Code)
+			}
+		})
+	}
+}
+
+func TestProviderTracing(t *testing.T) {
+	recorder := recordSpans(t)
+	downloader := NewDataDownloader(zap.NewNop(), time.Second)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	ctx, parent := trace.StartSpan(context.Background(), "test")
+	_, err := downloader.fetchData(ctx, providerDental, server.URL)
+	parent.End()
+	assert.NoError(t, err)
+
+	span := recorder.span("clinic/provider/fetch")
+	if assert.NotNil(t, span) {
+		assert.Equal(t, parent.SpanContext().TraceID, span.TraceID)
+		assert.Equal(t, parent.SpanContext().SpanID, span.ParentSpanID)
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Equal(t, providerDental, span.Attributes["provider"])
+		assert.Equal(t, int64(http.StatusOK), span.Attributes["http.status_code"])
+
+		assert.Equal(t, "00-"+span.TraceID.String()+"-"+span.SpanID.String()+"-01", traceparent)
+	}
+}